@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+)
+
+func printAST(args []string) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	lisp := flags.Bool("lisp", false, "print the tree as S-expressions instead of an indented tree")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	b, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	stmts, err := parse(b)
+	if err != nil {
+		return 2
+	}
+
+	var out string
+	if *lisp {
+		out, err = lox.NewASTPrinter().PrintProgram(stmts)
+	} else {
+		out, err = lox.NewTreePrinter().PrintProgram(stmts)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	fmt.Print(out)
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeCoverage of a script to an LCOV file and an HTML report, either is optional, and its summary
+// to the standard error
+func writeCoverage(c *lox.Coverage, lcov, html, script string) error {
+	if lcov != "" {
+		if err := writeFile(lcov, func(f *os.File) error { return c.WriteLCOV(f, script) }); err != nil {
+			return err
+		}
+	}
+
+	if html != "" {
+		source, err := ioutil.ReadFile(script)
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(html, func(f *os.File) error { return c.WriteHTML(f, filepath.Base(script), source) })
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(os.Stderr, c.Summary())
+	return err
+}
+
+// writeFile creates a file and writes it with the given function
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
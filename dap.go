@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"golox/dap"
+	"os"
+)
+
+// serveDAP runs a debug adapter over the standard input and output
+func serveDAP(args []string) int {
+	if len(args) != 0 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	if err := dap.NewAdapter(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,512 @@
+// Package dap implements a Debug Adapter Protocol server for Lox, so editors can debug scripts
+// with the pause and step machinery of the interpreter.
+//
+// A session launches a single program which runs in its own goroutine. Lox has no threads, so
+// the program is reported as a single thread whose stack frames are the calls being executed.
+package dap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golox/lox"
+	"golox/rpc"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// threadID of the only thread of a program
+const threadID = 1
+
+// NewAdapter constructor, the adapter reads messages from in and writes them to out
+func NewAdapter(in io.Reader, out io.Writer) *Adapter {
+	return &Adapter{
+		in:     rpc.NewReader(in),
+		out:    rpc.NewWriter(out),
+		paused: make(chan lox.PauseReason),
+		resume: make(chan lox.StepMode),
+		done:   make(chan error, 1),
+	}
+}
+
+// Adapter between a debugger client and the interpreter
+type Adapter struct {
+	in  *rpc.Reader
+	out *rpc.Writer
+	// mu guards the sequence number of the messages, which are also written by the program
+	// goroutine
+	mu  sync.Mutex
+	seq int
+
+	path        string
+	program     []lox.Stmt
+	interpreter *lox.Interpreter
+	debugger    *lox.Debugger
+	stopOnEntry bool
+
+	// paused receives the pauses of the program, which waits for resume to continue; done
+	// receives the error returned by the program when it ends
+	paused chan lox.PauseReason
+	resume chan lox.StepMode
+	done   chan error
+
+	running       bool
+	stopped       bool
+	disconnecting bool
+	// handles are the values referenced by the variables of a stopped program
+	handles []interface{}
+}
+
+// Run serves the client until it disconnects or closes the input
+func (a *Adapter) Run() error {
+	requests := make(chan *request)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			content, err := a.in.Read()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			var r request
+			if err := json.Unmarshal(content, &r); err != nil {
+				errs <- err
+				return
+			}
+			requests <- &r
+		}
+	}()
+
+	for {
+		if a.disconnecting && !a.running {
+			return nil
+		}
+
+		select {
+		case r := <-requests:
+			if err := a.handle(r); err != nil {
+				return err
+			}
+		case reason := <-a.paused:
+			if err := a.stop(reason); err != nil {
+				return err
+			}
+		case err := <-a.done:
+			if err := a.exit(err); err != nil {
+				return err
+			}
+		case err := <-errs:
+			if a.running {
+				a.debugger.Terminate()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// handler of a request, it returns the body of the response
+type handler func(a *Adapter, arguments json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":        (*Adapter).handleInitialize,
+	"launch":            (*Adapter).handleLaunch,
+	"setBreakpoints":    (*Adapter).handleSetBreakpoints,
+	"configurationDone": (*Adapter).handleConfigurationDone,
+	"threads":           (*Adapter).handleThreads,
+	"stackTrace":        (*Adapter).handleStackTrace,
+	"scopes":            (*Adapter).handleScopes,
+	"variables":         (*Adapter).handleVariables,
+	"evaluate":          (*Adapter).handleEvaluate,
+	"continue":          resumeWith(lox.Continue),
+	"next":              resumeWith(lox.StepOver),
+	"stepIn":            resumeWith(lox.StepIn),
+	"stepOut":           resumeWith(lox.StepOut),
+	"pause":             (*Adapter).handlePause,
+	"terminate":         (*Adapter).handleDisconnect,
+	"disconnect":        (*Adapter).handleDisconnect,
+}
+
+func (a *Adapter) handle(r *request) error {
+	h, ok := handlers[r.Command]
+	if !ok {
+		return a.respond(r, nil, fmt.Errorf("unsupported command '%s'", r.Command))
+	}
+
+	body, err := h(a, r.Arguments)
+	if err := a.respond(r, body, err); err != nil {
+		return err
+	}
+
+	// Some requests must be followed by events or resume the program, which is done once
+	// they got their response
+	switch {
+	case err != nil:
+		return nil
+	case r.Command == "launch":
+		return a.event("initialized", nil)
+	case r.Command == "configurationDone":
+		a.start()
+	case a.stopped && body != nil:
+		if mode, ok := body.(resumed); ok {
+			a.stopped = false
+			a.handles = nil
+			a.resume <- mode.mode
+		}
+	case r.Command == "disconnect" || r.Command == "terminate":
+		a.disconnecting = true
+		if a.stopped {
+			a.stopped = false
+			a.resume <- lox.Terminate
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) send(m interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.seq++
+	switch m := m.(type) {
+	case *response:
+		m.Seq = a.seq
+	case *event:
+		m.Seq = a.seq
+	}
+	return a.out.Write(m)
+}
+
+func (a *Adapter) respond(r *request, body interface{}, err error) error {
+	res := &response{Type: "response", RequestSeq: r.Seq, Command: r.Command, Success: err == nil}
+	if err != nil {
+		res.Message = err.Error()
+	} else if _, ok := body.(resumed); !ok {
+		res.Body = body
+	}
+	return a.send(res)
+}
+
+func (a *Adapter) event(name string, body interface{}) error {
+	return a.send(&event{Type: "event", Event: name, Body: body})
+}
+
+func (a *Adapter) handleInitialize(_ json.RawMessage) (interface{}, error) {
+	return &Capabilities{
+		SupportsConfigurationDoneRequest: true,
+		SupportsConditionalBreakpoints:   true,
+		SupportsEvaluateForHovers:        true,
+		SupportsTerminateRequest:         true,
+	}, nil
+}
+
+// handleLaunch loads the program, which starts once the client is done setting breakpoints
+func (a *Adapter) handleLaunch(arguments json.RawMessage) (interface{}, error) {
+	if a.debugger != nil {
+		return nil, errors.New("a program was already launched")
+	}
+
+	var args LaunchArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(args.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, errs := lox.NewScanner(string(b)).ScanTokens()
+	var stmts []lox.Stmt
+	if len(tokens) > 0 {
+		var parseErrs []error
+		stmts, parseErrs = lox.NewParser(tokens).Parse()
+		errs = append(errs, parseErrs...)
+	}
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	a.path = path
+	a.program = stmts
+	a.stopOnEntry = args.StopOnEntry
+	a.debugger = lox.NewDebugger(stmts, a.pause)
+	a.interpreter = lox.NewInterpreter(
+		lox.WithOutput(&output{adapter: a}),
+		lox.WithDebugger(a.debugger),
+		lox.WithCapabilities(lox.AllCapabilities()...),
+	)
+
+	if _, err := lox.NewResolver(a.interpreter).Resolve(stmts); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// output of the program sent as output events
+type output struct {
+	adapter *Adapter
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	err := o.adapter.event("output", map[string]string{"category": "stdout", "output": string(p)})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// handleSetBreakpoints replaces every breakpoint of the program
+func (a *Adapter) handleSetBreakpoints(arguments json.RawMessage) (interface{}, error) {
+	if a.debugger == nil {
+		return nil, errors.New("there is no program to set breakpoints on")
+	}
+
+	var args SetBreakpointsArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	for _, b := range a.debugger.Breakpoints() {
+		a.debugger.ClearBreakpoint(b.Line)
+	}
+
+	breakpoints := []Breakpoint{}
+	for _, sb := range args.Breakpoints {
+		b := Breakpoint{Line: sb.Line, Verified: true}
+		if _, err := a.debugger.SetBreakpoint(sb.Line, sb.Condition); err != nil {
+			b.Verified = false
+			b.Message = err.Error()
+		}
+		breakpoints = append(breakpoints, b)
+	}
+
+	return map[string]interface{}{"breakpoints": breakpoints}, nil
+}
+
+func (a *Adapter) handleConfigurationDone(_ json.RawMessage) (interface{}, error) {
+	if a.debugger == nil {
+		return nil, errors.New("there is no program to run")
+	}
+	if a.running {
+		return nil, errors.New("the program is already running")
+	}
+	return nil, nil
+}
+
+// start the program in its own goroutine
+func (a *Adapter) start() {
+	a.running = true
+	go func() {
+		err := a.interpreter.Interpret(a.program)
+		a.interpreter.Close()
+		a.done <- err
+	}()
+}
+
+// pause is called by the debugger in the program goroutine, which blocks until it is resumed
+func (a *Adapter) pause(_ *lox.Debugger, reason lox.PauseReason) lox.StepMode {
+	if reason == lox.PauseEntry && !a.stopOnEntry {
+		return lox.Continue
+	}
+
+	a.paused <- reason
+	return <-a.resume
+}
+
+// stop notifies the client that the program paused
+func (a *Adapter) stop(reason lox.PauseReason) error {
+	if a.disconnecting {
+		a.resume <- lox.Terminate
+		return nil
+	}
+
+	a.stopped = true
+	return a.event("stopped", map[string]interface{}{
+		"reason":            string(reason),
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	})
+}
+
+// exit notifies the client that the program ended
+func (a *Adapter) exit(err error) error {
+	a.running = false
+	a.stopped = false
+
+	code := 0
+	if err != nil && err != lox.ErrTerminated {
+		code = 2
+		if err := a.event("output", map[string]string{"category": "stderr", "output": err.Error() + "\n"}); err != nil {
+			return err
+		}
+	}
+
+	if err := a.event("exited", map[string]int{"exitCode": code}); err != nil {
+		return err
+	}
+	return a.event("terminated", nil)
+}
+
+// resumed is returned by the handlers of the requests that resume the program
+type resumed struct {
+	mode lox.StepMode
+}
+
+func resumeWith(mode lox.StepMode) handler {
+	return func(a *Adapter, _ json.RawMessage) (interface{}, error) {
+		if !a.stopped {
+			return nil, errors.New("the program is not paused")
+		}
+		return resumed{mode: mode}, nil
+	}
+}
+
+func (a *Adapter) handlePause(_ json.RawMessage) (interface{}, error) {
+	if !a.running {
+		return nil, errors.New("the program is not running")
+	}
+
+	a.debugger.Pause()
+	return nil, nil
+}
+
+func (a *Adapter) handleDisconnect(_ json.RawMessage) (interface{}, error) {
+	if a.running && !a.stopped {
+		a.debugger.Terminate()
+	}
+	return nil, nil
+}
+
+func (a *Adapter) handleThreads(_ json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"threads": []Thread{{ID: threadID, Name: "main"}}}, nil
+}
+
+// frame returns the index in the stack of a frame id, ids are the index plus one so that the
+// innermost frame is used when the id is missing
+func (a *Adapter) frame(id int) (int, error) {
+	if !a.stopped {
+		return 0, errors.New("the program is not paused")
+	}
+
+	if id == 0 {
+		return 0, nil
+	}
+
+	if id < 0 || id > len(a.debugger.Stack()) {
+		return 0, fmt.Errorf("invalid frame id %d", id)
+	}
+	return id - 1, nil
+}
+
+func (a *Adapter) handleStackTrace(_ json.RawMessage) (interface{}, error) {
+	if !a.stopped {
+		return nil, errors.New("the program is not paused")
+	}
+
+	source := Source{Name: filepath.Base(a.path), Path: a.path}
+	frames := []StackFrame{}
+	for i, f := range a.debugger.Stack() {
+		frames = append(frames, StackFrame{
+			ID:     i + 1,
+			Name:   f.Name,
+			Source: source,
+			Line:   f.Position.Line,
+			Column: f.Position.Column,
+		})
+	}
+
+	return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+// reference returns a handle to the variables of a scope or the properties of an instance, 0 when
+// there is nothing to reference
+func (a *Adapter) reference(v interface{}) int {
+	if _, ok := v.([]lox.Binding); !ok && a.debugger.Properties(v) == nil {
+		return 0
+	}
+
+	a.handles = append(a.handles, v)
+	return len(a.handles)
+}
+
+func (a *Adapter) handleScopes(arguments json.RawMessage) (interface{}, error) {
+	var args FrameArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	frame, err := a.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []Scope{}
+	for _, s := range a.debugger.Scopes(frame) {
+		scopes = append(scopes, Scope{
+			Name:               s.Name,
+			VariablesReference: a.reference(s.Variables),
+			Expensive:          s.Name == "Globals",
+		})
+	}
+	return map[string]interface{}{"scopes": scopes}, nil
+}
+
+func (a *Adapter) handleVariables(arguments json.RawMessage) (interface{}, error) {
+	var args VariablesArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	if !a.stopped || args.VariablesReference < 1 || args.VariablesReference > len(a.handles) {
+		return nil, fmt.Errorf("invalid variables reference %d", args.VariablesReference)
+	}
+
+	bindings, ok := a.handles[args.VariablesReference-1].([]lox.Binding)
+	if !ok {
+		bindings = a.debugger.Properties(a.handles[args.VariablesReference-1])
+	}
+
+	variables := []Variable{}
+	for _, b := range bindings {
+		variables = append(variables, Variable{
+			Name:               b.Name,
+			Value:              lox.Stringify(b.Value),
+			VariablesReference: a.reference(b.Value),
+		})
+	}
+	return map[string]interface{}{"variables": variables}, nil
+}
+
+func (a *Adapter) handleEvaluate(arguments json.RawMessage) (interface{}, error) {
+	var args EvaluateArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	frame, err := a.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := a.debugger.Evaluate(frame, strings.TrimSpace(args.Expression))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"result":             lox.Stringify(v),
+		"variablesReference": a.reference(v),
+	}, nil
+}
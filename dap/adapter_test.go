@@ -0,0 +1,103 @@
+package dap_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"golox/dap"
+	"golox/rpc"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// contains returns true when every field of expected is in actual, arrays must have the same
+// length and their elements are compared the same way
+func contains(actual, expected interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range e {
+			if !contains(a[k], v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !contains(a[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+func TestAdapter_RecordedSession(t *testing.T) {
+	f, err := os.Open("testdata/session.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	adapterIn, clientOut := io.Pipe()
+	clientIn, adapterOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- dap.NewAdapter(adapterIn, adapterOut).Run()
+		adapterOut.Close()
+	}()
+
+	in := rpc.NewReader(clientIn)
+	out := rpc.NewWriter(clientOut)
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var message interface{}
+		if err := json.Unmarshal([]byte(line[2:]), &message); err != nil {
+			t.Fatalf("line %d: %v", n, err)
+		}
+
+		switch line[:2] {
+		case "->":
+			if err := out.Write(message); err != nil {
+				t.Fatalf("line %d: %v", n, err)
+			}
+		case "<-":
+			content, err := in.Read()
+			if err != nil {
+				t.Fatalf("line %d: %v", n, err)
+			}
+
+			var actual interface{}
+			if err := json.Unmarshal(content, &actual); err != nil {
+				t.Fatalf("line %d: %v", n, err)
+			}
+
+			if !contains(actual, message) {
+				t.Fatalf("line %d: expected a message like\n%s\ngot\n%s", n, line[2:], content)
+			}
+		default:
+			t.Fatalf("line %d: unexpected direction %q", n, line[:2])
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const debugHelp = `Commands:
+    break <line> [if <expression>]    set a breakpoint, optionally conditional (b)
+    clear <line>                      remove a breakpoint
+    breakpoints                       list the breakpoints
+    continue                          run until the next breakpoint (c)
+    step                              step into the next line (s)
+    next                              step over the next line (n)
+    out                               step out of the current function (o)
+    locals                            print the variables of the selected frame (l)
+    print <expression>                evaluate an expression in the selected frame (p)
+    stack                             print the call stack (bt)
+    frame <n>                         select a frame of the call stack (f)
+    list                              print the source around the current line
+    quit                              terminate the program (q)
+    help                              print this help (h)
+`
+
+func debug(args []string) int {
+	if len(args) != 1 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	stmts, err := parse(b)
+	if err != nil {
+		return 2
+	}
+
+	s := &debugSession{
+		path:  args[0],
+		lines: strings.Split(string(b), "\n"),
+		input: bufio.NewScanner(os.Stdin),
+	}
+
+	debugger := lox.NewDebugger(stmts, s.pause)
+	interpreter := lox.NewInterpreter(lox.WithDebugger(debugger), lox.WithCapabilities(lox.AllCapabilities()...))
+	defer interpreter.Close()
+	if _, err := lox.NewResolver(interpreter).Resolve(stmts); err != nil {
+		report(err)
+		return 2
+	}
+
+	fmt.Println("Type 'help' for a list of commands.")
+	err = interpreter.Interpret(stmts)
+	if err == lox.ErrTerminated {
+		return 0
+	}
+	if err != nil {
+		report(err)
+		return 2
+	}
+
+	fmt.Println("Program finished.")
+	return 0
+}
+
+// debugSession reads the commands of the user while the program is paused
+type debugSession struct {
+	path  string
+	lines []string
+	input *bufio.Scanner
+	// frame selected in the call stack, 0 is the innermost one
+	frame int
+}
+
+func (s *debugSession) pause(d *lox.Debugger, reason lox.PauseReason) lox.StepMode {
+	s.frame = 0
+	f := d.Stack()[0]
+	fmt.Printf("Paused on %s at %s:%d in %s\n", reason, s.path, f.Position.Line, f.Name)
+	s.printLine(f.Position.Line, true)
+
+	for {
+		fmt.Print("(debug) ")
+		if !s.input.Scan() {
+			fmt.Println()
+			return lox.Terminate
+		}
+
+		fields := strings.Fields(s.input.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		command, arg := fields[0], strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s.input.Text()), fields[0]))
+		switch command {
+		case "continue", "c":
+			return lox.Continue
+		case "step", "s":
+			return lox.StepIn
+		case "next", "n":
+			return lox.StepOver
+		case "out", "o":
+			return lox.StepOut
+		case "quit", "q":
+			return lox.Terminate
+		case "break", "b":
+			s.setBreakpoint(d, arg)
+		case "clear":
+			line, err := strconv.Atoi(arg)
+			if err != nil {
+				fmt.Println("usage: clear <line>")
+				continue
+			}
+			d.ClearBreakpoint(line)
+		case "breakpoints":
+			for _, b := range d.Breakpoints() {
+				fmt.Printf("%s:%d", s.path, b.Line)
+				if b.Condition != "" {
+					fmt.Printf(" if %s", b.Condition)
+				}
+				fmt.Printf(" (hits: %d)\n", b.Hits)
+			}
+		case "locals", "l":
+			s.printLocals(d)
+		case "print", "p":
+			v, err := d.Evaluate(s.frame, arg)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Println(lox.Stringify(v))
+		case "stack", "bt":
+			s.printStack(d)
+		case "frame", "f":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 || n >= len(d.Stack()) {
+				fmt.Println("usage: frame <n>, where n is a frame listed by 'stack'")
+				continue
+			}
+			s.frame = n
+			s.printStack(d)
+		case "list":
+			line := d.Stack()[s.frame].Position.Line
+			for l := line - 3; l <= line+3; l++ {
+				s.printLine(l, l == line)
+			}
+		case "help", "h":
+			fmt.Print(debugHelp)
+		default:
+			fmt.Printf("Unknown command '%s'. Type 'help' for a list of commands.\n", command)
+		}
+	}
+}
+
+func (s *debugSession) setBreakpoint(d *lox.Debugger, arg string) {
+	lineArg, condition := arg, ""
+	if i := strings.Index(arg, " if "); i >= 0 {
+		lineArg, condition = strings.TrimSpace(arg[:i]), strings.TrimSpace(arg[i+4:])
+	}
+
+	line, err := strconv.Atoi(lineArg)
+	if err != nil {
+		fmt.Println("usage: break <line> [if <expression>]")
+		return
+	}
+
+	if _, err := d.SetBreakpoint(line, condition); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Breakpoint set at %s:%d\n", s.path, line)
+}
+
+// printLocals prints the variables of every scope of the selected frame but the globals, the
+// shadowed ones are left out
+func (s *debugSession) printLocals(d *lox.Debugger) {
+	seen := map[string]bool{}
+	for _, scope := range d.Scopes(s.frame) {
+		if scope.Name == "Globals" {
+			break
+		}
+
+		for _, v := range scope.Variables {
+			if seen[v.Name] {
+				continue
+			}
+			seen[v.Name] = true
+			fmt.Printf("%s = %s\n", v.Name, lox.Stringify(v.Value))
+		}
+	}
+}
+
+func (s *debugSession) printStack(d *lox.Debugger) {
+	for n, f := range d.Stack() {
+		marker := " "
+		if n == s.frame {
+			marker = ">"
+		}
+		fmt.Printf("%s #%d %s at %s:%d\n", marker, n, f.Name, s.path, f.Position.Line)
+	}
+}
+
+func (s *debugSession) printLine(line int, current bool) {
+	if line < 1 || line > len(s.lines) {
+		return
+	}
+
+	marker := "  "
+	if current {
+		marker = "=>"
+	}
+	fmt.Printf("%s %4d | %s\n", marker, line, s.lines[line-1])
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"golox/lox"
+)
+
+// coded is implemented by the errors that carry one of the codes documented by 'golox explain'
+type coded interface {
+	Code() string
+}
+
+// report prints a diagnostic followed by a hint on how to get more information about it
+func report(err error) {
+	fmt.Println(err)
+	if e, ok := err.(*lox.RuntimeError); ok && e.Stack() != nil {
+		fmt.Print(lox.StackTrace(e.Stack()))
+	}
+	if c, ok := err.(coded); ok {
+		if _, ok := lox.Explain(c.Code()); ok {
+			fmt.Printf("For more information about this error, try 'golox explain %s'.\n", c.Code())
+		}
+	}
+}
+
+func explain(args []string) int {
+	if len(args) == 0 {
+		for _, e := range lox.Explanations() {
+			fmt.Printf("%-32s %s\n", e.Code, e.Summary)
+		}
+		return 0
+	}
+
+	if len(args) > 1 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	e, ok := lox.Explain(args[0])
+	if !ok {
+		fmt.Printf("Unknown error code '%s'. Run 'golox explain' to list every code.\n", args[0])
+		return 1
+	}
+
+	fmt.Print(e)
+	return 0
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// formatFlags of the fmt command
+type formatFlags struct {
+	write bool
+	list  bool
+	diff  bool
+}
+
+func format(args []string) int {
+	var ff formatFlags
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.BoolVar(&ff.write, "w", false, "write the result to the source file instead of the standard output")
+	flags.BoolVar(&ff.list, "l", false, "list the files whose formatting differs from golox fmt's")
+	flags.BoolVar(&ff.diff, "d", false, "display diffs instead of rewriting files")
+	if err := flags.Parse(args); err != nil {
+		fmt.Print(usage)
+		return 1
+	}
+
+	if flags.NArg() == 0 {
+		if ff.write {
+			fmt.Println("golox fmt: cannot use -w with the standard input")
+			return 1
+		}
+
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+
+		if err := formatSource("<standard input>", b, ff); err != nil {
+			report(err)
+			return 2
+		}
+		return 0
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || (p != path && filepath.Ext(p) != ".lox") {
+				return nil
+			}
+
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return err
+			}
+
+			if err := formatSource(p, b, ff); err != nil {
+				fmt.Printf("%s: ", p)
+				report(err)
+				status = 2
+			}
+			return nil
+		})
+
+		if err != nil {
+			fmt.Println(err)
+			status = 2
+		}
+	}
+
+	return status
+}
+
+func formatSource(path string, b []byte, ff formatFlags) error {
+	out, err := lox.Format(string(b))
+	if err != nil {
+		return err
+	}
+
+	changed := !bytes.Equal(b, []byte(out))
+	if ff.list && changed {
+		fmt.Println(path)
+	}
+
+	if ff.diff && changed {
+		fmt.Print(unifiedDiff(path+".orig", path, string(b), out))
+	}
+
+	if ff.write && changed {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path, []byte(out), info.Mode()); err != nil {
+			return err
+		}
+	}
+
+	if !ff.list && !ff.diff && !ff.write {
+		fmt.Print(out)
+	}
+
+	return nil
+}
+
+// unifiedDiff between two texts with three lines of context
+func unifiedDiff(fromName, toName, from, to string) string {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type edit struct {
+		kind byte
+		line string
+		// ai and bi are the indexes of the line in each text before the edit is applied
+		ai, bi int
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			edits = append(edits, edit{' ', a[i], i, j})
+			i++
+			j++
+		} else if i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]) {
+			edits = append(edits, edit{'-', a[i], i, j})
+			i++
+		} else {
+			edits = append(edits, edit{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(edits); {
+		if edits[start].kind == ' ' {
+			start++
+			continue
+		}
+
+		// Extend the hunk while changes are closer than twice the context to each other
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		last := start
+		for k := start; k < len(edits) && k <= last+2*context; k++ {
+			if edits[k].kind != ' ' {
+				last = k
+			}
+		}
+		end := last + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		var body strings.Builder
+		removed, added := 0, 0
+		for _, e := range edits[first:end] {
+			fmt.Fprintf(&body, "%c%s\n", e.kind, e.line)
+			if e.kind != '+' {
+				removed++
+			}
+			if e.kind != '-' {
+				added++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", edits[first].ai+1, removed, edits[first].bi+1, added)
+		out.WriteString(body.String())
+		start = end
+	}
+
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
@@ -1,8 +1,10 @@
 module golox
 
-go 1.16
+go 1.18
+
+require github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
 
 require (
-	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
+	github.com/chzyer/test v1.0.0 // indirect
 	golang.org/x/sys v0.0.0-20210809222454-d867a43fc93e // indirect
 )
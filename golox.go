@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/chzyer/readline"
 	"golox/lox"
@@ -8,29 +9,140 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+const usage = `Usage:
+    golox                   start the interactive prompt
+    golox [run] [-profile <file>] [-coverage <file>] [-coverage-html <file>]
+                [-timeout <duration>] [-max-steps <n>] [-root <dir>] <script>
+                            run a script, optionally writing a pprof profile of it, or
+                            its coverage in LCOV and HTML formats, bounding how long it
+                            runs and confining the files it accesses to a directory
+    golox explain [code]    explain an error code, or list all of them
+    golox ast [-lisp] <script>
+                            print the syntax tree of a script
+    golox fmt [-w] [-l] [-d] [path ...]
+                            format scripts, or the standard input when there are no paths
+    golox test [-run <regexp>] [path ...]
+                            run the test functions of the *_test.lox files
+    golox debug <script>    run a script in an interactive step debugger
+    golox dap               start a debug adapter over the standard input and output
+    golox lsp               start a language server over the standard input and output
+`
+
 func main() {
-	if len(os.Args) > 2 {
-		fmt.Println("Usage: lox [path to script]")
-	} else if len(os.Args) == 2 {
-		path, err := filepath.Abs(os.Args[1])
-		if err != nil {
-			fmt.Printf("Unable to find path %s", path)
-			os.Exit(1)
-		}
-		err = runFile(path)
-		if err != nil {
-			os.Exit(2)
-		}
-	} else {
+	if len(os.Args) < 2 {
 		err := runPrompt()
 		if err != nil {
 			os.Exit(3)
 		}
+		return
+	}
+
+	switch os.Args[1] {
+	case "explain":
+		os.Exit(explain(os.Args[2:]))
+	case "ast":
+		os.Exit(printAST(os.Args[2:]))
+	case "fmt":
+		os.Exit(format(os.Args[2:]))
+	case "test":
+		os.Exit(test(os.Args[2:]))
+	case "debug":
+		os.Exit(debug(os.Args[2:]))
+	case "dap":
+		os.Exit(serveDAP(os.Args[2:]))
+	case "lsp":
+		os.Exit(serveLSP(os.Args[2:]))
+	case "run":
+		os.Exit(runScript(os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		os.Exit(runScript(os.Args[1:]))
 	}
 }
 
+// runFlags of the run command
+type runFlags struct {
+	profile      string
+	coverage     string
+	coverageHTML string
+	timeout      time.Duration
+	maxSteps     int
+	root         string
+}
+
+func runScript(args []string) int {
+	var rf runFlags
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.StringVar(&rf.profile, "profile", "", "write a pprof profile to the given file and print the time spent in every function")
+	flags.StringVar(&rf.coverage, "coverage", "", "write the statements and branches that ran to the given file in the LCOV format")
+	flags.StringVar(&rf.coverageHTML, "coverage-html", "", "write the source annotated with its coverage to the given HTML file")
+	flags.DurationVar(&rf.timeout, "timeout", 0, "stop the script when it runs for longer than the given duration")
+	flags.IntVar(&rf.maxSteps, "max-steps", 0, "stop the script when it evaluates more than the given number of statements, expressions and loop iterations")
+	flags.StringVar(&rf.root, "root", "", "confine the files the script reads and writes to the given directory")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	path, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		fmt.Printf("Unable to find path %s", path)
+		return 1
+	}
+
+	options := []lox.Option{lox.WithCapabilities(lox.AllCapabilities()...)}
+	var profiler *lox.Profiler
+	if rf.profile != "" {
+		profiler = lox.NewProfiler()
+		options = append(options, lox.WithProfiler(profiler))
+	}
+
+	var coverage *lox.Coverage
+	if rf.coverage != "" || rf.coverageHTML != "" {
+		coverage = lox.NewCoverage()
+		options = append(options, lox.WithCoverage(coverage))
+	}
+
+	if rf.timeout > 0 {
+		options = append(options, lox.WithDeadline(time.Now().Add(rf.timeout)))
+	}
+	if rf.maxSteps > 0 {
+		options = append(options, lox.WithStepLimit(rf.maxSteps))
+	}
+	if rf.root != "" {
+		options = append(options, lox.WithRoot(rf.root))
+	}
+
+	err = runFile(path, options...)
+
+	// Scripts that fail at runtime are still worth profiling and measuring
+	if _, ok := err.(*lox.RuntimeError); err == nil || ok {
+		if profiler != nil {
+			if err := writeProfile(profiler, rf.profile, flags.Arg(0)); err != nil {
+				fmt.Println(err)
+				return 1
+			}
+		}
+
+		if coverage != nil {
+			if err := writeCoverage(coverage, rf.coverage, rf.coverageHTML, path); err != nil {
+				fmt.Println(err)
+				return 1
+			}
+		}
+	}
+
+	if err != nil {
+		return 2
+	}
+
+	return 0
+}
+
 func runPrompt() error {
 	fmt.Println("Welcome to lox command prompt!")
 	defer func() {
@@ -60,7 +172,7 @@ func runPrompt() error {
 	return nil
 }
 
-func runFile(path string) error {
+func runFile(path string, options ...lox.Option) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return err
@@ -71,42 +183,50 @@ func runFile(path string) error {
 		return err
 	}
 
-	return run(b)
+	return run(b, options...)
 }
 
-func run(b []byte) error {
-	tokens, err := lox.NewScanner(string(b)).ScanTokens()
-	if err != nil {
+func run(b []byte, options ...lox.Option) error {
+	e, err := parse(b)
+	if err != nil || e == nil {
 		return err
 	}
 
-	if len(tokens) == 0 {
-		return nil
-	}
-
-	parser := lox.NewParser(tokens)
-	e, errs := parser.Parse()
-	if len(errs) > 0 {
-		for _, err := range errs {
-			fmt.Println(err)
-		}
-
-		return err
-	}
-
-	interpreter := lox.NewInterpreter()
+	interpreter := lox.NewInterpreter(options...)
+	defer interpreter.Close()
 
 	_, err = lox.NewResolver(interpreter).Resolve(e)
 	if err != nil {
-		fmt.Println(err)
+		report(err)
 		return err
 	}
 
 	err = interpreter.Interpret(e)
 	if err != nil {
-		fmt.Println(err)
+		report(err)
 		return err
 	}
 
 	return nil
 }
+
+// parse the source reporting every lexical and syntax error found
+func parse(b []byte) ([]lox.Stmt, error) {
+	tokens, errs := lox.NewScanner(string(b)).ScanTokens()
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	parser := lox.NewParser(tokens)
+	e, parseErrs := parser.Parse()
+	errs = append(errs, parseErrs...)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			report(err)
+		}
+
+		return nil, errs[0]
+	}
+
+	return e, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunLimits(t *testing.T) {
+	dir := t.TempDir()
+	forever := filepath.Join(dir, "forever.lox")
+	if err := os.WriteFile(forever, []byte("for {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	counter := filepath.Join(dir, "counter.lox")
+	if err := os.WriteFile(counter, []byte("for var i = 0; i < 3; i = i + 1 {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args []string
+		code int
+	}{
+		"timeout":          {[]string{"-timeout", "50ms", forever}, 2},
+		"max steps":        {[]string{"-max-steps", "1000", forever}, 2},
+		"within max steps": {[]string{"-max-steps", "1000", counter}, 0},
+		"invalid timeout":  {[]string{"-timeout", "soon", counter}, 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if code := runScript(test.args); code != test.code {
+				t.Fatalf("expected exit code %d, got %d", test.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package lox
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// SymbolKind of a declared name
+type SymbolKind int
+
+const (
+	// VariableSymbol declared with var
+	VariableSymbol SymbolKind = iota
+	// ParameterSymbol of a function or method
+	ParameterSymbol
+	// FunctionSymbol declared with fun
+	FunctionSymbol
+	// ClassSymbol declared with class
+	ClassSymbol
+	// MethodSymbol of a class
+	MethodSymbol
+	// FieldSymbol of a class, declared by assigning a property of this
+	FieldSymbol
+	// NativeSymbol provided by the interpreter
+	NativeSymbol
+)
+
+var symbolKinds = map[SymbolKind]string{
+	VariableSymbol:  "var",
+	ParameterSymbol: "parameter",
+	FunctionSymbol:  "fun",
+	ClassSymbol:     "class",
+	MethodSymbol:    "method",
+	FieldSymbol:     "field",
+	NativeSymbol:    "native",
+}
+
+func (k SymbolKind) String() string {
+	return symbolKinds[k]
+}
+
+// Position in a source. Lines and columns start at 1 and columns count runes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) before(o Position) bool {
+	return p.Line < o.Line || (p.Line == o.Line && p.Column < o.Column)
+}
+
+func position(t *Token) Position {
+	return Position{Line: t.line, Column: t.column}
+}
+
+// Symbol is a name declared in a program
+type Symbol struct {
+	Name string
+	Kind SymbolKind
+	// Detail is a short description of the symbol, like the signature of a function
+	Detail string
+	// Position of the name in its declaration, zero for natives
+	Position Position
+	// Container is the function, method or class where the symbol is declared, nil for the top
+	// level symbols
+	Container *Symbol
+	// Children are the symbols declared inside of a function, method or class
+	Children []*Symbol
+	// end is the position of the brace that closes the scope of the symbol, zero when it lasts
+	// until the end of the source
+	end Position
+}
+
+// Occurrence of a symbol in a source, either its declaration or a reference to it
+type Occurrence struct {
+	Symbol   *Symbol
+	Position Position
+	// Length of the name in runes
+	Length int
+}
+
+// Diagnostic is an error found in a source
+type Diagnostic struct {
+	// Position of the error, zero when it is unknown
+	Position    Position
+	Code        string
+	Description string
+}
+
+// Analysis of a source for editor tooling. Unlike running a program, analyzing it does not stop
+// at the first error and keeps track of where every symbol is declared and referenced.
+type Analysis struct {
+	Diagnostics []Diagnostic
+	// Symbols in declaration order, natives first. There are no symbols when the source has
+	// lexical or syntax errors.
+	Symbols     []*Symbol
+	occurrences []Occurrence
+	// pending holds the names that could not be resolved by scope, they are linked by name once
+	// every symbol is declared
+	pending    []pendingName
+	containers []*Symbol
+}
+
+type pendingName struct {
+	token    *Token
+	property bool
+}
+
+// Analyze a source
+func Analyze(source string) *Analysis {
+	a := &Analysis{}
+
+	tokens, errs := NewScanner(source).ScanTokens()
+	var stmts []Stmt
+	if len(tokens) > 0 {
+		var parseErrs []error
+		stmts, parseErrs = NewParser(tokens).Parse()
+		errs = append(errs, parseErrs...)
+	}
+
+	if len(errs) == 0 && len(stmts) > 0 {
+		interpreter := NewInterpreter(WithOutput(ioutil.Discard))
+		names := interpreter.globals.names()
+		sort.Strings(names)
+		for _, name := range names {
+			a.Symbols = append(a.Symbols, &Symbol{Name: name, Kind: NativeSymbol, Detail: "native " + name})
+		}
+
+		r := NewResolver(interpreter)
+		r.analysis = a
+		_, err := r.resolve(stmts)
+		errs = r.errors(err)
+		a.link()
+	}
+
+	for _, err := range errs {
+		a.Diagnostics = append(a.Diagnostics, diagnostic(err))
+	}
+
+	return a
+}
+
+func diagnostic(err error) Diagnostic {
+	var e *Error
+	switch err := err.(type) {
+	case *SyntaxError:
+		e = &err.err
+	case *RuntimeError:
+		e = &err.err
+	default:
+		return Diagnostic{Description: err.Error()}
+	}
+
+	d := Diagnostic{Code: e.code, Description: e.description}
+	if e.line != nil && e.column != nil {
+		d.Position = Position{Line: *e.line, Column: *e.column}
+	}
+	return d
+}
+
+// At returns the occurrence of a symbol that spans the given position
+func (a *Analysis) At(p Position) (Occurrence, bool) {
+	for _, o := range a.occurrences {
+		if o.Position.Line == p.Line && o.Position.Column <= p.Column && p.Column <= o.Position.Column+o.Length {
+			return o, true
+		}
+	}
+	return Occurrence{}, false
+}
+
+// References returns every occurrence of a symbol, its declaration included, in source order
+func (a *Analysis) References(s *Symbol) []Occurrence {
+	var refs []Occurrence
+	for _, o := range a.occurrences {
+		if o.Symbol == s {
+			refs = append(refs, o)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Position.before(refs[j].Position)
+	})
+	return refs
+}
+
+// Outline returns the top level symbols, natives excluded
+func (a *Analysis) Outline() []*Symbol {
+	var outline []*Symbol
+	for _, s := range a.Symbols {
+		if s.Container == nil && s.Kind != NativeSymbol {
+			outline = append(outline, s)
+		}
+	}
+	return outline
+}
+
+// Visible returns the variables, functions and classes that can be referenced at the given
+// position. Top level functions and classes are visible everywhere since they are looked up when
+// they are called.
+func (a *Analysis) Visible(p Position) []*Symbol {
+	var visible []*Symbol
+	for _, s := range a.Symbols {
+		switch {
+		case s.Kind == MethodSymbol || s.Kind == FieldSymbol:
+			continue
+		case s.Kind == NativeSymbol:
+		case s.Container == nil && (s.Kind == FunctionSymbol || s.Kind == ClassSymbol):
+		case !s.Position.before(p):
+			continue
+		case s.end != Position{} && !p.before(s.end):
+			continue
+		}
+		visible = append(visible, s)
+	}
+	return visible
+}
+
+// The following methods are called by the resolver while it walks the program. They do nothing
+// when the analysis is nil, which is the case when the program is about to be run.
+
+func (a *Analysis) declare(t *Token, kind SymbolKind) *Symbol {
+	if a == nil {
+		return nil
+	}
+
+	var container *Symbol
+	if len(a.containers) > 0 {
+		container = a.containers[len(a.containers)-1]
+	}
+	return a.add(t, kind, container)
+}
+
+func (a *Analysis) add(t *Token, kind SymbolKind, container *Symbol) *Symbol {
+	s := &Symbol{
+		Name:      t.lexeme,
+		Kind:      kind,
+		Detail:    kind.String() + " " + t.lexeme,
+		Position:  position(t),
+		Container: container,
+	}
+
+	if container != nil {
+		container.Children = append(container.Children, s)
+	}
+	a.Symbols = append(a.Symbols, s)
+	a.reference(t, s)
+	return s
+}
+
+// method declares a method in the current class
+func (a *Analysis) method(t *Token) *Symbol {
+	return a.declare(t, MethodSymbol)
+}
+
+// field declares a property assigned to this in the closest class, the first assignment is
+// considered its declaration
+func (a *Analysis) field(t *Token) {
+	if a == nil {
+		return
+	}
+
+	for i := len(a.containers) - 1; i >= 0; i-- {
+		class := a.containers[i]
+		if class.Kind != ClassSymbol {
+			continue
+		}
+
+		for _, child := range class.Children {
+			if child.Name == t.lexeme {
+				a.reference(t, child)
+				return
+			}
+		}
+
+		a.add(t, FieldSymbol, class)
+		return
+	}
+}
+
+// signature sets the detail of a function or method
+func (a *Analysis) signature(s *Symbol, keyword string, params []*Token) {
+	if a == nil || s == nil {
+		return
+	}
+
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.lexeme
+	}
+	s.Detail = keyword + " " + s.Name + "(" + strings.Join(names, ", ") + ")"
+}
+
+func (a *Analysis) inherits(class *Symbol, super *Variable) {
+	if a == nil || class == nil || super == nil {
+		return
+	}
+	class.Detail += " < " + super.token.lexeme
+}
+
+// enter a function, method or class, the symbols declared until leaving it are its children
+func (a *Analysis) enter(s *Symbol) {
+	if a == nil || s == nil {
+		return
+	}
+	a.containers = append(a.containers, s)
+}
+
+func (a *Analysis) leave(s *Symbol) {
+	if a == nil || s == nil {
+		return
+	}
+	a.containers = a.containers[:len(a.containers)-1]
+}
+
+// end sets the brace that closes the scope of a symbol
+func (a *Analysis) end(s *Symbol, brace *Token) {
+	if a == nil || s == nil || brace == nil {
+		return
+	}
+	s.end = position(brace)
+}
+
+func (a *Analysis) reference(t *Token, s *Symbol) {
+	if a == nil || s == nil {
+		return
+	}
+	a.occurrences = append(a.occurrences, Occurrence{Symbol: s, Position: position(t), Length: len([]rune(t.lexeme))})
+}
+
+func (a *Analysis) unresolved(t *Token, property bool) {
+	if a == nil {
+		return
+	}
+	a.pending = append(a.pending, pendingName{token: t, property: property})
+}
+
+// link the names that could not be resolved by scope to a symbol with the same name. Properties
+// are linked to methods and fields, and variables to top level symbols before nested ones.
+func (a *Analysis) link() {
+	for _, p := range a.pending {
+		var found *Symbol
+		for _, s := range a.Symbols {
+			if s.Name != p.token.lexeme {
+				continue
+			}
+
+			isProperty := s.Kind == MethodSymbol || s.Kind == FieldSymbol
+			if isProperty != p.property {
+				continue
+			}
+
+			if found == nil || (found.Container != nil && s.Container == nil) {
+				found = s
+			}
+		}
+
+		a.reference(p.token, found)
+	}
+	a.pending = nil
+}
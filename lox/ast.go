@@ -18,6 +18,7 @@ type ExpressionVisitor interface {
 	visitSet(e *Set) (interface{}, error)
 	visitLogical(e *Logical) (interface{}, error)
 	visitVariable(e *Variable) (interface{}, error)
+	visitInterpolation(e *Interpolation) (interface{}, error)
 }
 
 // NewUnary Expression constructor
@@ -229,6 +230,25 @@ func(e *Call) Accept(v ExpressionVisitor) (interface{}, error) {
 	return v.visitCall(e)
 }
 
+// NewInterpolation Expression constructor
+func NewInterpolation(quote *Token, parts []Expression) *Interpolation {
+	return &Interpolation{
+		quote: quote,
+		parts: parts,
+	}
+}
+
+// Interpolation Expression implementation
+type Interpolation struct {
+	quote *Token
+	parts []Expression
+}
+
+// Accept method of the visitor pattern it calls the proper visit method
+func(e *Interpolation) Accept(v ExpressionVisitor) (interface{}, error) {
+	return v.visitInterpolation(e)
+}
+
 // Stmt representation
 type Stmt interface {
 	Accept(v StmtVisitor) (interface{}, error)
@@ -248,8 +268,9 @@ type StmtVisitor interface {
 }
 
 // NewForStmt Stmt constructor
-func NewForStmt(initializer Stmt, condition Expression, increment Expression, body *BlockStmt, br *bool, cont *bool) *ForStmt {
+func NewForStmt(keyword *Token, initializer Stmt, condition Expression, increment Expression, body *BlockStmt, br *bool, cont *bool) *ForStmt {
 	return &ForStmt{
+		keyword: keyword,
 		initializer: initializer,
 		condition: condition,
 		increment: increment,
@@ -261,6 +282,7 @@ func NewForStmt(initializer Stmt, condition Expression, increment Expression, bo
 
 // ForStmt Stmt implementation
 type ForStmt struct {
+	keyword *Token
 	initializer Stmt
 	condition Expression
 	increment Expression
@@ -275,14 +297,16 @@ func(e *ForStmt) Accept(v StmtVisitor) (interface{}, error) {
 }
 
 // NewPrintStmt Stmt constructor
-func NewPrintStmt(expression Expression) *PrintStmt {
+func NewPrintStmt(keyword *Token, expression Expression) *PrintStmt {
 	return &PrintStmt{
+		keyword: keyword,
 		expression: expression,
 	}
 }
 
 // PrintStmt Stmt implementation
 type PrintStmt struct {
+	keyword *Token
 	expression Expression
 }
 
@@ -292,8 +316,9 @@ func(e *PrintStmt) Accept(v StmtVisitor) (interface{}, error) {
 }
 
 // NewCircuitBreakStmt Stmt constructor
-func NewCircuitBreakStmt(value *bool, statement Stmt) *CircuitBreakStmt {
+func NewCircuitBreakStmt(keyword *Token, value *bool, statement Stmt) *CircuitBreakStmt {
 	return &CircuitBreakStmt{
+		keyword: keyword,
 		value: value,
 		statement: statement,
 	}
@@ -301,6 +326,7 @@ func NewCircuitBreakStmt(value *bool, statement Stmt) *CircuitBreakStmt {
 
 // CircuitBreakStmt Stmt implementation
 type CircuitBreakStmt struct {
+	keyword *Token
 	value *bool
 	statement Stmt
 }
@@ -311,8 +337,9 @@ func(e *CircuitBreakStmt) Accept(v StmtVisitor) (interface{}, error) {
 }
 
 // NewIfStmt Stmt constructor
-func NewIfStmt(expression Expression, thenBranch *BlockStmt, elseBranch *BlockStmt) *IfStmt {
+func NewIfStmt(keyword *Token, expression Expression, thenBranch *BlockStmt, elseBranch *BlockStmt) *IfStmt {
 	return &IfStmt{
+		keyword: keyword,
 		expression: expression,
 		thenBranch: thenBranch,
 		elseBranch: elseBranch,
@@ -321,6 +348,7 @@ func NewIfStmt(expression Expression, thenBranch *BlockStmt, elseBranch *BlockSt
 
 // IfStmt Stmt implementation
 type IfStmt struct {
+	keyword *Token
 	expression Expression
 	thenBranch *BlockStmt
 	elseBranch *BlockStmt
@@ -374,15 +402,17 @@ func(e *VarStmt) Accept(v StmtVisitor) (interface{}, error) {
 }
 
 // NewBlockStmt Stmt constructor
-func NewBlockStmt(statements []Stmt) *BlockStmt {
+func NewBlockStmt(statements []Stmt, brace *Token) *BlockStmt {
 	return &BlockStmt{
 		statements: statements,
+		brace: brace,
 	}
 }
 
 // BlockStmt Stmt implementation
 type BlockStmt struct {
 	statements []Stmt
+	brace *Token
 }
 
 // Accept method of the visitor pattern it calls the proper visit method
@@ -0,0 +1,112 @@
+package lox
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Capability grants scripts access to something outside of the interpreter. Natives check the one
+// they need before running. File system capabilities may be restricted to a directory by appending
+// it, as "fs:read:/data", without it they grant access to every file.
+type Capability string
+
+// Capabilities natives check
+const (
+	// ClockCapability reads the time
+	ClockCapability Capability = "clock"
+	// RandomCapability generates random numbers
+	RandomCapability Capability = "random"
+	// EnvCapability reads the environment variables
+	EnvCapability Capability = "env"
+	// FSReadCapability reads files
+	FSReadCapability Capability = "fs:read"
+	// FSWriteCapability creates, writes and removes files
+	FSWriteCapability Capability = "fs:write"
+)
+
+// AllCapabilities grants everything, the command line runs scripts with them
+func AllCapabilities() []Capability {
+	return []Capability{ClockCapability, RandomCapability, EnvCapability, FSReadCapability, FSWriteCapability}
+}
+
+// WithCapabilities grants the given capabilities to the scripts. Scripts have none by default, so
+// natives that reach outside of the interpreter fail with a PermissionDenied error.
+func WithCapabilities(capabilities ...Capability) Option {
+	return func(i *Interpreter) {
+		i.capabilities = append(i.capabilities, capabilities...)
+	}
+}
+
+// require returns a PermissionDenied error unless the script was granted the capability
+func (i *Interpreter) require(t *Token, c Capability) error {
+	for _, granted := range i.capabilities {
+		if granted == c {
+			return nil
+		}
+	}
+	return PermissionDenied(t, string(c))
+}
+
+// WithRoot confines the files scripts access to a directory, whatever the capabilities they were
+// granted, and resolves their relative paths against it
+func WithRoot(dir string) Option {
+	return func(i *Interpreter) {
+		i.root = dir
+	}
+}
+
+// requirePath returns a PermissionDenied error unless the script was granted the file system
+// capability for the path, either everywhere or in a directory that contains it, and the path is
+// within the root. It returns the absolute path the script refers to.
+func (i *Interpreter) requirePath(t *Token, c Capability, path string) (string, error) {
+	if i.root != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(i.root, path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", PermissionDenied(t, string(c)+":"+path)
+	}
+
+	if i.root != "" && !within(i.root, path) {
+		return "", PermissionDenied(t, string(c)+":"+path)
+	}
+
+	for _, granted := range i.capabilities {
+		if granted == c {
+			return path, nil
+		}
+
+		prefix := string(c) + ":"
+		if strings.HasPrefix(string(granted), prefix) && within(strings.TrimPrefix(string(granted), prefix), path) {
+			return path, nil
+		}
+	}
+	return "", PermissionDenied(t, string(c)+":"+path)
+}
+
+// within tells whether the path is the directory or is inside of it once their symbolic links are
+// followed, so links can't lead scripts out of it
+func within(dir, path string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(evalSymlinks(dir), evalSymlinks(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// evalSymlinks of the longest part of an absolute path that exists, the rest is kept as it is since
+// it can't be a link yet
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(evalSymlinks(parent), filepath.Base(path))
+}
@@ -0,0 +1,20 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv("GOLOX_TEST_ENV", "value")
+	out, err := interpret(t, `print env("GOLOX_TEST_ENV");
+print env("GOLOX_TEST_UNSET");
+`, lox.WithCapabilities(lox.EnvCapability))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "value\nnil\n"; out != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+}
@@ -0,0 +1,71 @@
+package lox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock the natives read the time from and sleep with. Tests inject a FakeClock so scripts that
+// depend on the time behave the same on every run.
+type Clock interface {
+	Now() time.Time
+	// Sleep for the duration, or until the context is done
+	Sleep(ctx context.Context, d time.Duration)
+}
+
+// WithClock sets the clock of the natives. Defaults to the system clock. Execution limits are
+// always checked against the system clock.
+func WithClock(c Clock) Option {
+	return func(i *Interpreter) {
+		i.clock = c
+	}
+}
+
+// systemClock reads the time of the operating system
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// NewFakeClock constructor of a clock stopped at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// FakeClock only moves forward when it is advanced or slept on, sleeping returns right away.
+// Sleeping for a negative duration leaves it as it is, as the system clock returns at once.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Sleep(_ context.Context, d time.Duration) {
+	if d > 0 {
+		c.Advance(d)
+	}
+}
+
+// Advance the clock by the given duration
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
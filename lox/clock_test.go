@@ -0,0 +1,40 @@
+package lox_test
+
+import (
+	"context"
+	"golox/lox"
+	"testing"
+	"time"
+)
+
+func TestSleepDeadline(t *testing.T) {
+	start := time.Now()
+	_, err := interpret(t, `time.sleep(time.hour);`, lox.WithCapabilities(lox.ClockCapability), lox.WithDeadline(time.Now().Add(10*time.Millisecond)))
+	if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.DeadlineExceededCode {
+		t.Fatalf("expected %s, got %v", lox.DeadlineExceededCode, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the sleep to stop at the deadline, it took %v", elapsed)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := lox.NewFakeClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if _, err := interpret(t, `time.sleep(time.hour);`, lox.WithCapabilities(lox.ClockCapability), lox.WithClock(clock)); err != nil {
+		t.Fatal(err)
+	}
+
+	if now := clock.Now(); now.Hour() != 1 {
+		t.Fatalf("expected the sleep to advance the clock by an hour, it is %v", now)
+	}
+}
+
+func TestFakeClockNegativeSleep(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := lox.NewFakeClock(now)
+	clock.Sleep(context.Background(), -time.Hour)
+
+	if !clock.Now().Equal(now) {
+		t.Fatalf("expected a negative sleep to leave the clock at %v, it is %v", now, clock.Now())
+	}
+}
@@ -0,0 +1,143 @@
+package lox_test
+
+import (
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	expectOutput       = regexp.MustCompile(`// expect: (.*)$`)
+	expectRuntimeError = regexp.MustCompile(`// expect runtime error: (.*)$`)
+	expectError        = regexp.MustCompile(`// error at (\d+:\d+: .*)$`)
+)
+
+// directoryOptions of the interpreters running the programs of a directory in testdata, the others
+// are granted no capabilities
+var directoryOptions = map[string]func() []lox.Option{
+	// The clock is stopped so the programs that read the time print the same on every run
+	"time": func() []lox.Option {
+		clock := lox.NewFakeClock(time.Date(2024, time.February, 29, 12, 30, 0, 0, time.UTC))
+		return []lox.Option{lox.WithClock(clock), lox.WithCapabilities(lox.ClockCapability)}
+	},
+}
+
+// expectations of a conformance program, written in its comments
+type expectations struct {
+	output []string
+	// errors are the lexical, syntax and resolution errors as "line:column: description"
+	errors []string
+	// runtimeError is the description of the error that stops the program, at runtimeErrorLine
+	runtimeError     string
+	runtimeErrorLine int
+}
+
+func parseExpectations(source string) expectations {
+	var e expectations
+	for n, line := range strings.Split(source, "\n") {
+		if m := expectOutput.FindStringSubmatch(line); m != nil {
+			e.output = append(e.output, m[1])
+		} else if m := expectRuntimeError.FindStringSubmatch(line); m != nil {
+			e.runtimeError = m[1]
+			e.runtimeErrorLine = n + 1
+		} else if m := expectError.FindStringSubmatch(line); m != nil {
+			e.errors = append(e.errors, m[1])
+		}
+	}
+	return e
+}
+
+// TestConformance runs every program in testdata and checks what it prints and the errors it
+// raises against the expectations in its comments:
+//
+//	print 1 + 2;   // expect: 3
+//	print -"a";    // expect runtime error: expected number, got string
+//	var a = ;      // error at 1:9: unhandled token ;
+func TestConformance(t *testing.T) {
+	var files []string
+	err := filepath.Walk("testdata", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(path) == ".lox" {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		file := file
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(file), "testdata/"), ".lox")
+		t.Run(name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var options []lox.Option
+			if directory, ok := directoryOptions[filepath.Base(filepath.Dir(file))]; ok {
+				options = directory()
+			}
+			conform(t, string(b), options...)
+		})
+	}
+}
+
+func conform(t *testing.T, source string, options ...lox.Option) {
+	expected := parseExpectations(source)
+
+	var errors []string
+	for _, d := range lox.Analyze(source).Diagnostics {
+		errors = append(errors, fmt.Sprintf("%d:%d: %s", d.Position.Line, d.Position.Column, d.Description))
+	}
+	if !equalLines(errors, expected.errors) {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected.errors, "\n"), strings.Join(errors, "\n"))
+	}
+	if len(errors) > 0 {
+		return
+	}
+
+	out, err := interpret(t, source, options...)
+	if output := strings.Split(strings.TrimSuffix(out, "\n"), "\n"); !equalLines(output, expected.output) {
+		t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(expected.output, "\n"), out)
+	}
+
+	if err == nil {
+		if expected.runtimeError != "" {
+			t.Fatalf("expected runtime error %q at line %d", expected.runtimeError, expected.runtimeErrorLine)
+		}
+		return
+	}
+
+	e, ok := err.(*lox.RuntimeError)
+	if !ok || e.Description() != expected.runtimeError || e.Position().Line != expected.runtimeErrorLine {
+		t.Fatalf("expected runtime error %q at line %d, got %v", expected.runtimeError, expected.runtimeErrorLine, err)
+	}
+}
+
+// equalLines compares lines treating no lines and a single empty one as the same
+func equalLines(a, b []string) bool {
+	a, b = nonEmpty(a), nonEmpty(b)
+	if len(a) != len(b) {
+		return false
+	}
+
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func nonEmpty(lines []string) []string {
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
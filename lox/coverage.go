@@ -0,0 +1,379 @@
+package lox
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NewCoverage constructor
+func NewCoverage() *Coverage {
+	return &Coverage{
+		statements: map[Stmt]*statementCoverage{},
+		branches:   map[interface{}]*branchCoverage{},
+	}
+}
+
+// Coverage records which statements of a program were executed, and which way its conditions
+// went: the then and else branches of the if statements, and whether the logical operators short
+// circuited or evaluated their right operand.
+type Coverage struct {
+	statements map[Stmt]*statementCoverage
+	branches   map[interface{}]*branchCoverage
+}
+
+type statementCoverage struct {
+	position Position
+	count    int
+}
+
+// branchCoverage of an if statement or a logical expression. For if statements taken counts the
+// then and the else branch, for logical expressions the short circuits and the evaluations of the
+// right operand.
+type branchCoverage struct {
+	position Position
+	taken    [2]int
+}
+
+// add the statements and branches of a program, so the ones that never run are reported too
+func (c *Coverage) add(program []Stmt) {
+	for _, s := range program {
+		c.addStatement(s)
+	}
+}
+
+func (c *Coverage) addStatement(s Stmt) {
+	if s == nil {
+		return
+	}
+
+	if p, ok := statementPosition(s); ok {
+		if _, ok := c.statements[s]; ok {
+			return
+		}
+		c.statements[s] = &statementCoverage{position: p}
+	}
+
+	switch s := s.(type) {
+	case *BlockStmt:
+		for _, s := range s.statements {
+			c.addStatement(s)
+		}
+	case *ExpressionStmt:
+		c.addExpression(s.expression)
+	case *PrintStmt:
+		c.addExpression(s.expression)
+	case *VarStmt:
+		c.addPart(s.initializer)
+	case *IfStmt:
+		c.addBranch(s, s.keyword)
+		c.addExpression(s.expression)
+		c.addStatement(s.thenBranch)
+		if s.elseBranch != nil {
+			c.addStatement(s.elseBranch)
+		}
+	case *ForStmt:
+		c.addStatement(s.initializer)
+		c.addExpression(s.condition)
+		c.addExpression(s.increment)
+		c.addStatement(s.body)
+	case *CircuitBreakStmt:
+		c.addPart(s.statement)
+	case *FunctionStmt:
+		c.addStatement(s.body)
+	case *ClassStmt:
+		for _, method := range s.methods {
+			c.addStatement(method.body)
+		}
+	}
+}
+
+// addPart of another statement, as the initializer of a variable, which is not counted by itself
+func (c *Coverage) addPart(s Stmt) {
+	if e, ok := s.(*ExpressionStmt); ok {
+		c.addExpression(e.expression)
+		return
+	}
+	c.addStatement(s)
+}
+
+func (c *Coverage) addExpression(e Expression) {
+	switch e := e.(type) {
+	case *Logical:
+		c.addBranch(e, e.operator)
+		c.addExpression(e.left)
+		c.addExpression(e.right)
+	case *Unary:
+		c.addExpression(e.right)
+	case *Binary:
+		c.addExpression(e.left)
+		c.addExpression(e.right)
+	case *Grouping:
+		c.addExpression(e.expression)
+	case *Interpolation:
+		for _, part := range e.parts {
+			c.addExpression(part)
+		}
+	case *Assign:
+		c.addExpression(e.value)
+	case *Get:
+		c.addExpression(e.object)
+	case *Set:
+		c.addExpression(e.object)
+		c.addExpression(e.value)
+	case *Call:
+		c.addExpression(e.callee)
+		for _, argument := range e.arguments {
+			c.addExpression(argument)
+		}
+	}
+}
+
+func (c *Coverage) addBranch(node interface{}, t *Token) {
+	if _, ok := c.branches[node]; !ok {
+		c.branches[node] = &branchCoverage{position: position(t)}
+	}
+}
+
+// statement is called before executing a statement
+func (c *Coverage) statement(s Stmt) {
+	if sc, ok := c.statements[s]; ok {
+		sc.count++
+	}
+}
+
+// branch is called with the if statement or the logical expression and the way it went, first is
+// true for the then branch and for short circuits
+func (c *Coverage) branch(node interface{}, first bool) {
+	b, ok := c.branches[node]
+	if !ok {
+		return
+	}
+
+	if first {
+		b.taken[0]++
+	} else {
+		b.taken[1]++
+	}
+}
+
+// Statements returns how many statements were executed out of the total
+func (c *Coverage) Statements() (covered, total int) {
+	for _, s := range c.statements {
+		if s.count > 0 {
+			covered++
+		}
+	}
+	return covered, len(c.statements)
+}
+
+// Branches returns how many branches were taken out of the total, every condition has two
+func (c *Coverage) Branches() (taken, total int) {
+	for _, b := range c.branches {
+		for _, n := range b.taken {
+			if n > 0 {
+				taken++
+			}
+		}
+	}
+	return taken, 2 * len(c.branches)
+}
+
+// lineCoverage is what ran in a line of the program
+type lineCoverage struct {
+	Line int
+	// Count is the times the statements of the line were executed, the largest of them, and
+	// Statements whether there is any
+	Count      int
+	Statements bool
+	// Branches of the conditions of the line, in the order they appear
+	Branches []*branchCoverage
+}
+
+// Covered is true when all statements and branches of the line ran
+func (l *lineCoverage) Covered() bool {
+	if l.Statements && l.Count == 0 {
+		return false
+	}
+
+	for _, b := range l.Branches {
+		if b.taken[0] == 0 || b.taken[1] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lines with statements or branches, sorted
+func (c *Coverage) lines() []*lineCoverage {
+	byLine := map[int]*lineCoverage{}
+	line := func(n int) *lineCoverage {
+		l, ok := byLine[n]
+		if !ok {
+			l = &lineCoverage{Line: n}
+			byLine[n] = l
+		}
+		return l
+	}
+
+	for _, s := range c.statements {
+		l := line(s.position.Line)
+		l.Statements = true
+		if s.count > l.Count {
+			l.Count = s.count
+		}
+	}
+
+	for _, b := range c.branches {
+		l := line(b.position.Line)
+		l.Branches = append(l.Branches, b)
+	}
+
+	var lines []*lineCoverage
+	for _, l := range byLine {
+		sort.Slice(l.Branches, func(i, j int) bool {
+			return l.Branches[i].position.Column < l.Branches[j].position.Column
+		})
+		lines = append(lines, l)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Line < lines[j].Line
+	})
+	return lines
+}
+
+// WriteLCOV writes the coverage of the program in the given file with the LCOV tracefile format
+func (c *Coverage) WriteLCOV(w io.Writer, filename string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TN:\nSF:%s\n", filename)
+
+	// every condition is a block of two branches, numbered in the order they appear
+	lines := c.lines()
+	block := 0
+	for _, l := range lines {
+		for _, br := range l.Branches {
+			for i, n := range br.taken {
+				// conditions that were never evaluated have no branches taken at all
+				taken := "-"
+				if br.taken[0]+br.taken[1] > 0 {
+					taken = fmt.Sprint(n)
+				}
+				fmt.Fprintf(&b, "BRDA:%d,%d,%d,%s\n", l.Line, block, i, taken)
+			}
+			block++
+		}
+	}
+
+	taken, total := c.Branches()
+	fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", total, taken)
+
+	found, hit := 0, 0
+	for _, l := range lines {
+		if !l.Statements {
+			continue
+		}
+
+		found++
+		if l.Count > 0 {
+			hit++
+		}
+		fmt.Fprintf(&b, "DA:%d,%d\n", l.Line, l.Count)
+	}
+	fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", found, hit)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML writes the source of the program annotated with its coverage
+func (c *Coverage) WriteHTML(w io.Writer, filename string, source []byte) error {
+	byLine := map[int]*lineCoverage{}
+	for _, l := range c.lines() {
+		byLine[l.Line] = l
+	}
+
+	type line struct {
+		Number int
+		Text   string
+		Class  string
+		Title  string
+	}
+
+	var lines []line
+	for n, text := range strings.Split(strings.TrimSuffix(string(source), "\n"), "\n") {
+		l := line{Number: n + 1, Text: text}
+		if lc, ok := byLine[n+1]; ok {
+			var titles []string
+			if lc.Statements {
+				titles = append(titles, fmt.Sprintf("executed %d times", lc.Count))
+			}
+			for _, b := range lc.Branches {
+				titles = append(titles, fmt.Sprintf("branches taken %d and %d times", b.taken[0], b.taken[1]))
+			}
+			l.Title = strings.Join(titles, ", ")
+
+			switch {
+			case lc.Covered():
+				l.Class = "covered"
+			case lc.Statements && lc.Count == 0:
+				l.Class = "uncovered"
+			default:
+				l.Class = "partial"
+			}
+		}
+		lines = append(lines, l)
+	}
+
+	covered, statements := c.Statements()
+	taken, branches := c.Branches()
+	return coverageTemplate.Execute(w, map[string]interface{}{
+		"Filename":   filename,
+		"Statements": percent(covered, statements),
+		"Branches":   percent(taken, branches),
+		"Lines":      lines,
+	})
+}
+
+// percent of a part of a total, 100 when the total is zero since there was nothing to cover
+func percent(part, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(total))
+}
+
+// Summary of the coverage, as printed after running a program
+func (c *Coverage) Summary() string {
+	covered, statements := c.Statements()
+	taken, branches := c.Branches()
+	return fmt.Sprintf("coverage: %s of statements, %s of branches", percent(covered, statements), percent(taken, branches))
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Filename}} coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { font-family: monospace; line-height: 1.3; }
+.number { color: #999; display: inline-block; text-align: right; width: 4em; margin-right: 1em; }
+.covered { background: #d4f4d4; }
+.uncovered { background: #f8d0d0; }
+.partial { background: #f8f0c0; }
+</style>
+</head>
+<body>
+<h1>{{.Filename}}</h1>
+<p>{{.Statements}} of statements, {{.Branches}} of branches</p>
+<pre>
+{{- range .Lines}}
+<span class="{{.Class}}" title="{{.Title}}"><span class="number">{{.Number}}</span>{{.Text}}</span>
+{{- end}}
+</pre>
+</body>
+</html>
+`))
@@ -0,0 +1,60 @@
+package lox_test
+
+import (
+	"bytes"
+	"golox/lox"
+	"strings"
+	"testing"
+)
+
+const covered = `fun sign(n) {
+    var s = "positive";
+    if (n < 0) {
+        s = "negative";
+    }
+    return s;
+}
+
+var a = sign(1);
+var b = a == "positive" || a == "zero";
+if (b && a == "negative") {
+    print "never";
+} else {
+    print a;
+}
+`
+
+func TestCoverage(t *testing.T) {
+	c := lox.NewCoverage()
+	if _, err := interpret(t, covered, lox.WithCoverage(c)); err != nil {
+		t.Fatal(err)
+	}
+
+	if covered, total := c.Statements(); covered != 8 || total != 10 {
+		t.Fatalf("expected 8 of 10 statements covered, got %d of %d", covered, total)
+	}
+
+	if taken, total := c.Branches(); taken != 4 || total != 8 {
+		t.Fatalf("expected 4 of 8 branches taken, got %d of %d", taken, total)
+	}
+
+	var lcov bytes.Buffer
+	if err := c.WriteLCOV(&lcov, "covered.lox"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, record := range []string{"SF:covered.lox", "DA:4,0", "DA:12,0", "DA:14,1", "BRDA:3,0,0,0", "BRDA:3,0,1,1", "BRDA:10,1,0,1", "BRDA:10,1,1,0", "LF:10", "LH:8"} {
+		if !strings.Contains(lcov.String(), record+"\n") {
+			t.Fatalf("missing %s in:\n%s", record, lcov.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := c.WriteHTML(&html, "covered.lox", []byte(covered)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(html.String(), `<span class="uncovered" title="executed 0 times"><span class="number">12</span>`) {
+		t.Fatalf("line 12 is not marked as uncovered in:\n%s", html.String())
+	}
+}
@@ -11,6 +11,9 @@ const (
 	class    dataType = "class"
 	object   dataType = "object"
 	function dataType = "function"
+	list     dataType = "list"
+	date     dataType = "date"
+	duration dataType = "duration"
 )
 
 func getDataType(v interface{}) dataType {
@@ -20,10 +23,16 @@ func getDataType(v interface{}) dataType {
 	if _, ok := v.(*Class); ok {
 		return class
 	}
-	if _, ok := v.(Instance); ok {
+	switch v.(type) {
+	case *Instance, *Namespace, *File:
 		return object
-	}
-	if _, ok := v.(*Function); ok {
+	case *List:
+		return list
+	case *Date:
+		return date
+	case *Duration:
+		return duration
+	case Callable:
 		return function
 	}
 
@@ -45,28 +54,30 @@ type Callable interface {
 // NewBaseCallable constructor
 func NewBaseCallable(parameters []*Token, closure *Environment) *BaseCallable {
 	return &BaseCallable{
-		parameters:  parameters,
-		environment: NewEnvironment(closure),
+		parameters: parameters,
+		closure:    closure,
 	}
 }
 
 // BaseCallable to create compositions
 type BaseCallable struct {
-	parameters  []*Token
-	environment *Environment
+	parameters []*Token
+	closure    *Environment
 }
 
-// Call method
-func (c *BaseCallable) Call(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+// bind the arguments of a call to the parameters in a new environment, so that every call has
+// its own variables
+func (c *BaseCallable) bind(paren *Token, arguments []interface{}) (*Environment, error) {
 	if len(c.parameters) != len(arguments) {
 		return nil, WrongNumberOfArguments(paren, len(arguments), len(c.parameters))
 	}
 
+	environment := NewEnvironment(c.closure)
 	for index, parameter := range c.parameters {
-		c.environment.define(parameter.lexeme, arguments[index])
+		environment.define(parameter.lexeme, arguments[index])
 	}
 
-	return nil, nil
+	return environment, nil
 }
 
 func NewFunction(statement *FunctionStmt, closure *Environment) *Function {
@@ -86,27 +97,40 @@ func (f *Function) String() string {
 	return "function"
 }
 
-func (f *Function) Bind(this *Instance) *Function {
-	method := &Function{
-		BaseCallable: f.BaseCallable,
-		statement:    f.statement,
+// name of the function, lambdas have none
+func (f *Function) name() string {
+	if f.statement.name == nil {
+		return "lambda"
 	}
-	method.environment.define("this", this)
-	return method
+	return f.statement.name.lexeme
+}
+
+// Bind returns a copy of the method whose closure defines this as the given instance
+func (f *Function) Bind(this *Instance) *Function {
+	closure := NewEnvironment(f.closure)
+	closure.define("this", this)
+	return NewFunction(f.statement, closure)
 }
 
 func (f *Function) Call(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
-	_, err := f.BaseCallable.Call(i, paren, arguments)
+	environment, err := f.bind(paren, arguments)
 	if err != nil {
 		return nil, err
 	}
+	i.allocate(environmentSize)
+
+	if len(i.frames) > i.depthLimit {
+		return nil, StackOverflow(paren, i.depthLimit, i.stack())
+	}
 
+	i.pushFrame(f.name(), f.statement.name, paren)
 	prev := *i.environment
-	i.environment = f.environment
+	i.environment = environment
 
 	defer func() {
 		i.environment = &prev
 		*f.statement.rt = false
+		i.popFrame()
 	}()
 
 	for _, stmt := range f.statement.body.statements {
@@ -115,7 +139,7 @@ func (f *Function) Call(i *Interpreter, paren *Token, arguments []interface{}) (
 			return nil, err
 		}
 
-		if _, ok := stmt.(*CircuitBreakStmt); ok {
+		if *f.statement.rt {
 			return s, nil
 		}
 	}
@@ -155,14 +179,24 @@ func NewInstance(class *Class, i *Interpreter, paren *Token, arguments []interfa
 		methods:    map[string]*Function{},
 	}
 
-	if class.super != nil {
-		for name, method := range class.super.methods {
+	// Methods are bound from the root of the class chain down, so overrides replace what they
+	// inherit
+	var chain []*Class
+	for c := class; c != nil; c = c.super {
+		chain = append(chain, c)
+	}
+
+	i.allocate(instanceSize)
+	for n := len(chain) - 1; n >= 0; n-- {
+		for name, method := range chain[n].methods {
 			instance.methods[name] = method.Bind(instance)
+			i.allocate(environmentSize)
+			i.allocate(functionSize)
 		}
 	}
 
-	for name, method := range class.methods {
-		instance.methods[name] = method.Bind(instance)
+	if err := i.quota(paren); err != nil {
+		return nil, err
 	}
 
 	if init, ok := instance.methods["init"]; ok {
@@ -175,6 +209,37 @@ func NewInstance(class *Class, i *Interpreter, paren *Token, arguments []interfa
 	return instance, nil
 }
 
+// NewNamespace constructor
+func NewNamespace(name string, members map[string]interface{}) *Namespace {
+	return &Namespace{
+		name:    name,
+		members: members,
+	}
+}
+
+// Namespace groups natives under a name, as the functions of math. Its members can't be assigned.
+type Namespace struct {
+	name    string
+	members map[string]interface{}
+}
+
+func (n *Namespace) String() string {
+	return "<namespace " + n.name + ">"
+}
+
+// Get a member of the namespace
+func (n *Namespace) Get(name *Token) (interface{}, error) {
+	if v, ok := n.members[name.lexeme]; ok {
+		return v, nil
+	}
+
+	var names []string
+	for member := range n.members {
+		names = append(names, member)
+	}
+	return nil, InvalidProperty(name, closestName(name.lexeme, names))
+}
+
 // Instance representation
 type Instance struct {
 	class      *Class
@@ -196,7 +261,25 @@ func (i *Instance) Get(property *Token) (interface{}, error) {
 		return m, nil
 	}
 
-	return nil, InvalidProperty(property)
+	return nil, InvalidProperty(property, closestName(property.lexeme, i.names()))
+}
+
+// names of every property and method reachable from the instance, including the ones
+// inherited through its class chain
+func (i *Instance) names() []string {
+	var names []string
+	for name := range i.properties {
+		names = append(names, name)
+	}
+	for name := range i.methods {
+		names = append(names, name)
+	}
+	for c := i.class; c != nil; c = c.super {
+		for name := range c.methods {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 func (i *Instance) Set(property *Token, value interface{}) {
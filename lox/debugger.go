@@ -0,0 +1,373 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrTerminated is returned by the interpreter when the debugger terminates the program
+var ErrTerminated = errors.New("program terminated by the debugger")
+
+// StepMode tells the debugger how to resume a paused program
+type StepMode int
+
+const (
+	// Continue until a breakpoint is hit
+	Continue StepMode = iota
+	// StepIn pauses at the next line, inside of the called functions too
+	StepIn
+	// StepOver pauses at the next line of the current function, or at its caller if it returns
+	StepOver
+	// StepOut pauses once the current function returns
+	StepOut
+	// Terminate the program
+	Terminate
+)
+
+// PauseReason tells why a program paused
+type PauseReason string
+
+const (
+	// PauseEntry is the reason of the pause before the first statement
+	PauseEntry PauseReason = "entry"
+	// PauseStep is the reason of the pauses after stepping
+	PauseStep PauseReason = "step"
+	// PauseBreakpoint is the reason of the pauses at breakpoints
+	PauseBreakpoint PauseReason = "breakpoint"
+	// PauseRequested is the reason of the pauses requested with Pause
+	PauseRequested PauseReason = "pause"
+)
+
+// Breakpoint of a debugger
+type Breakpoint struct {
+	Line int
+	// Condition is an expression, the breakpoint only pauses the program when it is truthy
+	Condition string
+	// Hits is the amount of times the breakpoint paused the program
+	Hits      int
+	condition Expression
+}
+
+// NewDebugger constructor. The program pauses before its first statement and every time it hits
+// a breakpoint or ends a step; pause is called with the reason, and the program resumes as told
+// by the mode it returns. The debugger is attached to an interpreter with WithDebugger.
+func NewDebugger(program []Stmt, pause func(d *Debugger, reason PauseReason) StepMode) *Debugger {
+	d := &Debugger{
+		lines:       map[int]bool{},
+		breakpoints: map[int]*Breakpoint{},
+		pause:       pause,
+		mode:        StepIn,
+		reason:      PauseEntry,
+	}
+
+	for _, s := range program {
+		d.addLines(s)
+	}
+	return d
+}
+
+// Debugger of a program, it pauses the interpreter before the statements that start a line.
+// Breakpoints can be changed, and the program paused or terminated, from other goroutines while
+// it runs; everything else must be done while it is paused.
+type Debugger struct {
+	interpreter *Interpreter
+	// lines where there is a statement to pause at
+	lines map[int]bool
+	pause func(d *Debugger, reason PauseReason) StepMode
+	// mu guards the breakpoints and the requests made while the program runs
+	mu          sync.Mutex
+	breakpoints map[int]*Breakpoint
+	pauseNow    bool
+	terminate   bool
+	// mode is how the program was resumed and depth the size of the stack when it was
+	mode  StepMode
+	depth int
+	// reason of the next pause when stepping
+	reason PauseReason
+	// evaluating is true while an expression is evaluated in a paused frame
+	evaluating bool
+}
+
+func (d *Debugger) addLines(s Stmt) {
+	if p, ok := statementPosition(s); ok {
+		d.lines[p.Line] = true
+	}
+
+	switch s := s.(type) {
+	case *BlockStmt:
+		for _, s := range s.statements {
+			d.addLines(s)
+		}
+	case *VarStmt:
+		if s.initializer != nil {
+			d.addLines(s.initializer)
+		}
+	case *IfStmt:
+		d.addLines(s.thenBranch)
+		if s.elseBranch != nil {
+			d.addLines(s.elseBranch)
+		}
+	case *ForStmt:
+		if s.initializer != nil {
+			d.addLines(s.initializer)
+		}
+		d.addLines(s.body)
+	case *CircuitBreakStmt:
+		if s.statement != nil {
+			d.addLines(s.statement)
+		}
+	case *FunctionStmt:
+		d.addLines(s.body)
+	case *ClassStmt:
+		for _, method := range s.methods {
+			d.addLines(method.body)
+		}
+	}
+}
+
+// SetBreakpoint at a line, replacing the breakpoint that was there. The condition is optional.
+func (d *Debugger) SetBreakpoint(line int, condition string) (*Breakpoint, error) {
+	if !d.lines[line] {
+		return nil, fmt.Errorf("there is no statement at line %d", line)
+	}
+
+	b := &Breakpoint{Line: line, Condition: condition}
+	if condition != "" {
+		e, err := parseExpression(condition)
+		if err != nil {
+			return nil, err
+		}
+		b.condition = e
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints[line] = b
+	return b, nil
+}
+
+// ClearBreakpoint at a line
+func (d *Debugger) ClearBreakpoint(line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.breakpoints, line)
+}
+
+// Breakpoints sorted by line, they are copies so they don't change while the program runs
+func (d *Debugger) Breakpoints() []Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var breakpoints []Breakpoint
+	for _, b := range d.breakpoints {
+		breakpoints = append(breakpoints, *b)
+	}
+
+	sort.Slice(breakpoints, func(i, j int) bool {
+		return breakpoints[i].Line < breakpoints[j].Line
+	})
+	return breakpoints
+}
+
+// Pause the running program before its next statement
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pauseNow = true
+}
+
+// Terminate the running program before its next statement
+func (d *Debugger) Terminate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.terminate = true
+}
+
+// Stack of the paused program, the innermost frame first
+func (d *Debugger) Stack() []*Frame {
+	frames := d.interpreter.frames
+	stack := make([]*Frame, len(frames))
+	for i, f := range frames {
+		stack[len(frames)-1-i] = f
+	}
+	return stack
+}
+
+// Scope is an environment of a paused frame
+type Scope struct {
+	// Name is Locals for the innermost environment, Globals for the outermost one and Enclosing
+	// for the ones in between
+	Name      string
+	Variables []Binding
+	// Environment identifies the scope, different frames may share environments
+	Environment *Environment
+}
+
+// Binding of a name to a value in a scope
+type Binding struct {
+	Name  string
+	Value interface{}
+}
+
+// Scopes of a frame of the stack, the innermost first
+func (d *Debugger) Scopes(frame int) []Scope {
+	var scopes []Scope
+	for e := d.Stack()[frame].environment; e != nil; e = e.enclosing {
+		name := "Enclosing"
+		if len(scopes) == 0 {
+			name = "Locals"
+		}
+		if e.enclosing == nil {
+			name = "Globals"
+		}
+
+		scopes = append(scopes, Scope{Name: name, Variables: variables(e.values), Environment: e})
+	}
+	return scopes
+}
+
+// Properties of an instance sorted by name, nil for any other value
+func (d *Debugger) Properties(v interface{}) []Binding {
+	instance, ok := v.(*Instance)
+	if !ok {
+		return nil
+	}
+	return variables(instance.properties)
+}
+
+func variables(values map[string]interface{}) []Binding {
+	vars := make([]Binding, 0, len(values))
+	for name, value := range values {
+		vars = append(vars, Binding{Name: name, Value: value})
+	}
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// Evaluate an expression in a frame of the stack. The debugger does not pause while evaluating.
+func (d *Debugger) Evaluate(frame int, source string) (interface{}, error) {
+	e, err := parseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	return d.evaluate(d.Stack()[frame], e)
+}
+
+func (d *Debugger) evaluate(f *Frame, e Expression) (interface{}, error) {
+	i := d.interpreter
+	prev := i.environment
+	d.evaluating = true
+	defer func() {
+		i.environment = prev
+		d.evaluating = false
+	}()
+
+	i.environment = f.environment
+	return i.evaluate(e)
+}
+
+// before is called by the interpreter before executing a statement of the current frame
+func (d *Debugger) before(newLine bool) error {
+	if d.evaluating {
+		return nil
+	}
+
+	d.mu.Lock()
+	terminate, requested := d.terminate, d.pauseNow
+	d.pauseNow = false
+	d.mu.Unlock()
+
+	if terminate {
+		return ErrTerminated
+	}
+
+	depth := len(d.interpreter.frames)
+	pause := false
+	switch d.mode {
+	case StepIn:
+		pause = newLine || depth != d.depth
+	case StepOver:
+		pause = depth < d.depth || (depth == d.depth && newLine)
+	case StepOut:
+		pause = depth < d.depth
+	}
+
+	reason := d.reason
+	if newLine && (!pause || reason == PauseEntry) && d.hit(d.interpreter.frame()) {
+		pause = true
+		reason = PauseBreakpoint
+	}
+
+	if !pause && requested {
+		pause = true
+		reason = PauseRequested
+	}
+
+	if !pause {
+		return nil
+	}
+
+	d.mode = d.pause(d, reason)
+	d.depth = depth
+	d.reason = PauseStep
+	if d.mode == Terminate {
+		return ErrTerminated
+	}
+	return nil
+}
+
+// hit returns true when there is a breakpoint at the line being executed by the frame and its
+// condition holds. Conditions that can't be evaluated pause the program so the user notices.
+func (d *Debugger) hit(f *Frame) bool {
+	d.mu.Lock()
+	b, ok := d.breakpoints[f.Position.Line]
+	d.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if b.condition != nil {
+		v, err := d.evaluate(f, b.condition)
+		if err == nil && !isTruthy(v) {
+			return false
+		}
+	}
+
+	d.mu.Lock()
+	b.Hits++
+	d.mu.Unlock()
+	return true
+}
+
+// parseExpression parses a single expression, the trailing semicolon is optional
+func parseExpression(source string) (Expression, error) {
+	tokens, errs := NewScanner(source).ScanTokens()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	p := NewParser(tokens)
+	if p.isAtEnd() {
+		return nil, UnhandledTokenError(p.current())
+	}
+
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.match(SEMICOLON)
+	if !p.isAtEnd() {
+		return nil, UnexpectedToken(p.current(), EOF)
+	}
+	return e, nil
+}
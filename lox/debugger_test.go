@@ -0,0 +1,117 @@
+package lox_test
+
+import (
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+const debuggee = `fun add(a, b) {
+    var c = a + b;
+    return c;
+}
+
+var x = add(1, 2);
+var y = add(x, 3);
+print y;
+`
+
+// debug runs the debuggee resuming it with the given modes, and returns where it paused
+func debug(t *testing.T, setup func(d *lox.Debugger), modes ...lox.StepMode) []string {
+	stmts := parse(t, debuggee)
+
+	var pauses []string
+	d := lox.NewDebugger(stmts, func(d *lox.Debugger, reason lox.PauseReason) lox.StepMode {
+		f := d.Stack()[0]
+		pauses = append(pauses, fmt.Sprintf("%s %s:%d", reason, f.Name, f.Position.Line))
+		if len(modes) == 0 {
+			return lox.Continue
+		}
+
+		mode := modes[0]
+		modes = modes[1:]
+		return mode
+	})
+	setup(d)
+
+	i := resolve(t, stmts, lox.WithOutput(ioutil.Discard), lox.WithDebugger(d))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+	return pauses
+}
+
+func TestDebugger_Step(t *testing.T) {
+	pauses := debug(t, func(*lox.Debugger) {}, lox.StepOver, lox.StepIn, lox.StepIn, lox.StepOut, lox.StepOver)
+
+	expected := []string{
+		"entry script:1",
+		"step script:6",
+		"step add:2",
+		"step add:3",
+		"step script:7",
+		"step script:8",
+	}
+	if !reflect.DeepEqual(pauses, expected) {
+		t.Fatalf("expected pauses %v, got %v", expected, pauses)
+	}
+}
+
+func TestDebugger_ConditionalBreakpoint(t *testing.T) {
+	pauses := debug(t, func(d *lox.Debugger) {
+		if _, err := d.SetBreakpoint(3, "a == 3"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := d.SetBreakpoint(5, ""); err == nil {
+			t.Fatal("expected an error setting a breakpoint on an empty line")
+		}
+	})
+
+	expected := []string{"entry script:1", "breakpoint add:3"}
+	if !reflect.DeepEqual(pauses, expected) {
+		t.Fatalf("expected pauses %v, got %v", expected, pauses)
+	}
+}
+
+func TestDebugger_Inspect(t *testing.T) {
+	stmts := parse(t, debuggee)
+
+	paused := false
+	d := lox.NewDebugger(stmts, func(d *lox.Debugger, reason lox.PauseReason) lox.StepMode {
+		if reason != lox.PauseBreakpoint {
+			return lox.Continue
+		}
+		paused = true
+
+		if len(d.Stack()) != 2 || d.Stack()[0].Call.Line != 7 {
+			t.Fatalf("unexpected stack %+v", d.Stack())
+		}
+
+		locals := d.Scopes(0)[0]
+		if locals.Name != "Locals" || len(locals.Variables) != 3 || locals.Variables[2].Value != 6.0 {
+			t.Fatalf("unexpected locals %+v", locals)
+		}
+
+		v, err := d.Evaluate(0, "c * x")
+		if err != nil || v != 18.0 {
+			t.Fatalf("expected 18, got %v, %v", v, err)
+		}
+
+		return lox.Terminate
+	})
+	if _, err := d.SetBreakpoint(3, "c > 5"); err != nil {
+		t.Fatal(err)
+	}
+
+	i := resolve(t, stmts, lox.WithOutput(ioutil.Discard), lox.WithDebugger(d))
+	if err := i.Interpret(stmts); err != lox.ErrTerminated {
+		t.Fatalf("expected the program to be terminated, got %v", err)
+	}
+
+	if !paused {
+		t.Fatal("the breakpoint was not hit")
+	}
+}
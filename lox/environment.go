@@ -53,9 +53,19 @@ func (e *Environment) assign(s string, value interface{}) bool {
 }
 
 func (e *Environment) assignAt(s string, value interface{}, distance int) bool {
-	if distance == 0 {
+	if distance <= 0 {
 		return e.assign(s, value)
 	}
+	return e.enclosing.assignAt(s, value, distance-1)
+}
 
-	return e.assignAt(s, value, distance-1)
+// names of every variable reachable from this environment
+func (e *Environment) names() []string {
+	var names []string
+	for env := e; env != nil; env = env.enclosing {
+		for name := range env.values {
+			names = append(names, name)
+		}
+	}
+	return names
 }
@@ -1,7 +1,9 @@
 package lox
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -10,6 +12,8 @@ const (
 	UnexpectedTokenCode = "UnexpectedToken"
 	// UnterminatedStringCode error
 	UnterminatedStringCode = "UnterminatedString"
+	// InvalidEscapeSequenceCode error
+	InvalidEscapeSequenceCode = "InvalidEscapeSequence"
 	// UnhandledTokenCode error
 	UnhandledTokenCode = "UnhandledToken"
 	// UnclosedParenthesisCode error
@@ -36,6 +40,8 @@ const (
 	ThisOutsideClassCode = "ThisOutsideClass"
 	// NoSelfInheritanceCode error
 	NoSelfInheritanceCode = "NoSelfInheritance"
+	// UndeclaredVariableCode error
+	UndeclaredVariableCode = "UndeclaredVariable"
 
 	// InvalidDataTypeCode error
 	InvalidDataTypeCode = "InvalidDataType"
@@ -55,6 +61,32 @@ const (
 	InvalidPropertyCode = "InvalidProperty"
 	// NotAClassCode error
 	NotAClassCode = "NotAClass"
+	// AssertionFailedCode error
+	AssertionFailedCode = "AssertionFailed"
+	// StepLimitExceededCode error
+	StepLimitExceededCode = "StepLimitExceeded"
+	// DeadlineExceededCode error
+	DeadlineExceededCode = "DeadlineExceeded"
+	// ExecutionCanceledCode error
+	ExecutionCanceledCode = "ExecutionCanceled"
+	// StackOverflowCode error
+	StackOverflowCode = "StackOverflow"
+	// MemoryLimitExceededCode error
+	MemoryLimitExceededCode = "MemoryLimitExceeded"
+	// PermissionDeniedCode error
+	PermissionDeniedCode = "PermissionDenied"
+	// InvalidIndexCode error
+	InvalidIndexCode = "InvalidIndex"
+	// InvalidNumberCode error
+	InvalidNumberCode = "InvalidNumber"
+	// InvalidFormatCode error
+	InvalidFormatCode = "InvalidFormat"
+	// FileErrorCode error
+	FileErrorCode = "FileError"
+	// InvalidJSONCode error
+	InvalidJSONCode = "InvalidJSON"
+	// InvalidTimeCode error
+	InvalidTimeCode = "InvalidTime"
 )
 
 // Error representation
@@ -63,6 +95,8 @@ type Error struct {
 	code        string
 	line        *int
 	column      *int
+	// stack of calls when a runtime error was raised, only kept by the errors where it matters
+	stack []*Frame
 }
 
 // Error string
@@ -74,6 +108,14 @@ func (e *Error) Error(t string) string {
 	return fmt.Sprintf("%s %s: %s. Code %v", t, on, e.description, e.code)
 }
 
+// didYouMean appends a suggestion to the description when there is one
+func didYouMean(description, suggestion string) string {
+	if suggestion == "" {
+		return description
+	}
+	return fmt.Sprintf("%s (did you mean '%s'?)", description, suggestion)
+}
+
 // SyntaxError representation
 type SyntaxError struct {
 	err Error
@@ -83,11 +125,16 @@ func (e *SyntaxError) Error() string {
 	return e.err.Error("SyntaxError")
 }
 
+// Code of the error
+func (e *SyntaxError) Code() string {
+	return e.err.code
+}
+
 // UnexpectedLexeme error
-func UnexpectedLexeme(t rune, line, column int) *SyntaxError {
+func UnexpectedLexeme(lexeme string, line, column int) *SyntaxError {
 	return &SyntaxError{
 		Error{
-			description: fmt.Sprintf("unexpected token '%s'", string(t)),
+			description: fmt.Sprintf("unexpected token '%s'", lexeme),
 			code:        UnexpectedTokenCode,
 			line:        &line,
 			column:      &column,
@@ -138,6 +185,19 @@ func UnterminatedStringError(line, column int) *SyntaxError {
 	}
 }
 
+// InvalidEscapeSequenceError raises when a backslash in a string is not followed by a valid escape
+// sequence, the position is the one of the backslash
+func InvalidEscapeSequenceError(sequence string, line, column int) *SyntaxError {
+	return &SyntaxError{
+		Error{
+			description: fmt.Sprintf("invalid escape sequence '%s'", sequence),
+			code:        InvalidEscapeSequenceCode,
+			line:        &line,
+			column:      &column,
+		},
+	}
+}
+
 // UnhandledTokenError error
 func UnhandledTokenError(t *Token) *SyntaxError {
 	return &SyntaxError{
@@ -310,6 +370,18 @@ func NoSelfInheritance(t *Token) *SyntaxError {
 	}
 }
 
+// UndeclaredVariable raises when a variable is used but it is not declared anywhere in the program
+func UndeclaredVariable(t *Token, suggestion string) *SyntaxError {
+	return &SyntaxError{
+		err: Error{
+			description: didYouMean(fmt.Sprintf("variable '%s' is not declared", t.lexeme), suggestion),
+			code:        UndeclaredVariableCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
 // RuntimeError representation
 type RuntimeError struct {
 	err Error
@@ -319,6 +391,29 @@ func (e *RuntimeError) Error() string {
 	return e.err.Error("RuntimeError")
 }
 
+// Code of the error
+func (e *RuntimeError) Code() string {
+	return e.err.code
+}
+
+// Description of the error, without its position and code
+func (e *RuntimeError) Description() string {
+	return e.err.description
+}
+
+// Position where the error happened, zero when it is unknown
+func (e *RuntimeError) Position() Position {
+	if e.err.line == nil || e.err.column == nil {
+		return Position{}
+	}
+	return Position{Line: *e.err.line, Column: *e.err.column}
+}
+
+// Stack of calls when the error was raised, the innermost frame first. It is nil for most errors.
+func (e *RuntimeError) Stack() []*Frame {
+	return e.err.stack
+}
+
 // InvalidDataTypeError raises when the interpreter receives an unexpected data type
 func InvalidDataTypeError(t *Token, got dataType, expected dataType) *RuntimeError {
 	return &RuntimeError{
@@ -356,10 +451,10 @@ func DivisionByZeroError(t *Token) *RuntimeError {
 }
 
 // UndefinedVariable raises when an undefined variable is called
-func UndefinedVariable(name string, t *Token) *RuntimeError {
+func UndefinedVariable(name string, t *Token, suggestion string) *RuntimeError {
 	return &RuntimeError{
 		Error{
-			description: fmt.Sprintf("undefined variable '%s'", name),
+			description: didYouMean(fmt.Sprintf("undefined variable '%s'", name), suggestion),
 			code:        UndefinedVariableCode,
 			line:        &t.line,
 			column:      &t.column,
@@ -404,10 +499,10 @@ func NotAnObject(t *Token) *RuntimeError {
 }
 
 // InvalidProperty raises when a property is being accessed but it does not exist
-func InvalidProperty(t *Token) *RuntimeError {
+func InvalidProperty(t *Token, suggestion string) *RuntimeError {
 	return &RuntimeError{
 		Error{
-			description: fmt.Sprintf("property '%s' is not defined", t.lexeme),
+			description: didYouMean(fmt.Sprintf("property '%s' is not defined", t.lexeme), suggestion),
 			code:        InvalidPropertyCode,
 			line:        &t.line,
 			column:      &t.column,
@@ -420,7 +515,172 @@ func NotAClass(t *Token) *RuntimeError {
 	return &RuntimeError{
 		Error{
 			description: fmt.Sprintf("cannot inherit from '%s', parent must be a class", t.lexeme),
-			code:        InvalidPropertyCode,
+			code:        NotAClassCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// AssertionFailed raises when one of the assertion natives, used by tests, does not hold
+func AssertionFailed(t *Token, description string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: description,
+			code:        AssertionFailedCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// StepLimitExceeded raises when the program evaluates more statements and expressions than allowed
+func StepLimitExceeded(t *Token, limit int) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("step limit of %d exceeded", limit),
+			code:        StepLimitExceededCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// DeadlineExceeded raises when the program is still running at its deadline
+func DeadlineExceeded(t *Token) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: "deadline exceeded",
+			code:        DeadlineExceededCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// ExecutionCanceled raises when the context the program runs in is done
+func ExecutionCanceled(t *Token, err error) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("execution canceled: %v", err),
+			code:        ExecutionCanceledCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// StackOverflow raises when a call is nested deeper than the limit, usually by a recursion that
+// never reaches its base case
+func StackOverflow(t *Token, limit int, stack []*Frame) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("stack overflow, calls nested deeper than %d", limit),
+			code:        StackOverflowCode,
+			line:        &t.line,
+			column:      &t.column,
+			stack:       stack,
+		},
+	}
+}
+
+// MemoryLimitExceeded raises when the program allocates more bytes or objects than allowed
+func MemoryLimitExceeded(t *Token, unit string, limit int) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("memory limit of %d %s exceeded", limit, unit),
+			code:        MemoryLimitExceededCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// PermissionDenied raises when a native needs a capability the script was not granted
+func PermissionDenied(t *Token, capability string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("permission denied, the script needs the '%s' capability", capability),
+			code:        PermissionDeniedCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// InvalidIndex raises when a position is not an integer within a string or a list
+func InvalidIndex(t *Token, index float64, length int) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("index %v out of range [0, %d)", index, length),
+			code:        InvalidIndexCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// InvalidNumber raises when a string converted to a number does not hold one
+func InvalidNumber(t *Token, s string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("%q is not a number", s),
+			code:        InvalidNumberCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// InvalidFormat raises when the layout given to format or printf does not match its arguments
+func InvalidFormat(t *Token, layout, reason string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("invalid format %q, %s", layout, reason),
+			code:        InvalidFormatCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// FileError raises when the file system fails an operation of a script. The path is the one the
+// script gave, since the absolute one may tell more about the host than it should.
+func FileError(t *Token, operation, path string, err error) *RuntimeError {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	return &RuntimeError{
+		Error{
+			description: fmt.Sprintf("cannot %s '%s': %v", operation, path, err),
+			code:        FileErrorCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// InvalidJSON raises when json.parse is given a text that is not JSON, or json.stringify a value
+// that can't be written as JSON
+func InvalidJSON(t *Token, reason string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: "invalid JSON, " + reason,
+			code:        InvalidJSONCode,
+			line:        &t.line,
+			column:      &t.column,
+		},
+	}
+}
+
+// InvalidTime raises when a date or a duration can't be parsed or built, or a time zone is unknown
+func InvalidTime(t *Token, reason string) *RuntimeError {
+	return &RuntimeError{
+		Error{
+			description: "invalid time, " + reason,
+			code:        InvalidTimeCode,
 			line:        &t.line,
 			column:      &t.column,
 		},
@@ -0,0 +1,427 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation is the long-form documentation of an error code
+type Explanation struct {
+	Code    string
+	Summary string
+	Details string
+	// Failing is a program that raises the error
+	Failing string
+	// Fixed is the failing program once the error was corrected
+	Fixed string
+}
+
+// String renders the explanation the way it is shown by the command line
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n\n", e.Code, e.Summary)
+	fmt.Fprintf(&b, "%s\n\n", wrap(e.Details, 80))
+	fmt.Fprintf(&b, "Erroneous code example:\n\n%s\n", indent(e.Failing))
+	fmt.Fprintf(&b, "Fixed code example:\n\n%s", indent(e.Fixed))
+	return b.String()
+}
+
+func wrap(text string, width int) string {
+	var b strings.Builder
+	length := 0
+	for _, word := range strings.Fields(text) {
+		if length > 0 && length+1+len(word) > width {
+			b.WriteString("\n")
+			length = 0
+		} else if length > 0 {
+			b.WriteString(" ")
+			length++
+		}
+		b.WriteString(word)
+		length += len(word)
+	}
+	return b.String()
+}
+
+func indent(code string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&b, "    %s\n", line)
+	}
+	return b.String()
+}
+
+// Explain returns the explanation of the given error code
+func Explain(code string) (*Explanation, bool) {
+	for _, e := range explanations {
+		if strings.EqualFold(e.Code, code) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Explanations of every error code, in the order they are declared
+func Explanations() []*Explanation {
+	return explanations
+}
+
+// manyArguments builds a call with more arguments than a function is allowed to receive
+func manyArguments() string {
+	var args []string
+	for i := 0; i <= 256; i++ {
+		args = append(args, fmt.Sprintf("%v", i))
+	}
+	return fmt.Sprintf("fun sum(a, b, c) {\n    print a + b + c;\n}\n\nsum(%s);\n", strings.Join(args, ", "))
+}
+
+var explanations = []*Explanation{
+	{
+		Code:    UnexpectedTokenCode,
+		Summary: "the scanner or the parser found a token it did not expect",
+		Details: "This error is raised when a character is not part of the language, or when a valid " +
+			"token appears in a place where the grammar expects something else. The most common " +
+			"cause is a missing semicolon at the end of a statement. The description lists the " +
+			"tokens that were expected instead.",
+		Failing: "var greeting = \"hello\"\nprint greeting;\n",
+		Fixed:   "var greeting = \"hello\";\nprint greeting;\n",
+	},
+	{
+		Code:    UnterminatedStringCode,
+		Summary: "a string literal is missing its closing quote",
+		Details: "String literals start and end with a double quote, or with a backtick for raw " +
+			"strings. When the end of the file is reached before the closing quote is found the " +
+			"string is unterminated.",
+		Failing: "print \"hello;\n",
+		Fixed:   "print \"hello\";\n",
+	},
+	{
+		Code:    InvalidEscapeSequenceCode,
+		Summary: "a backslash in a string is not followed by a valid escape sequence",
+		Details: "Strings between double quotes support the escape sequences \\n, \\t, \\r, \\0, " +
+			"\\\", \\\\ and \\u{...} with the hexadecimal code point of a character. Write \\\\ " +
+			"for a backslash, or use a raw string between backticks, where backslashes have no " +
+			"special meaning.",
+		Failing: "print \"C:\\lox\";\n",
+		Fixed:   "print `C:\\lox`;\n",
+	},
+	{
+		Code:    UnhandledTokenCode,
+		Summary: "an expression was expected but the token cannot start one",
+		Details: "Expressions must start with a literal, an identifier, 'this', a unary operator or " +
+			"an opening parenthesis. This error usually means an operand is missing.",
+		Failing: "var total = ;\nprint total;\n",
+		Fixed:   "var total = 0;\nprint total;\n",
+	},
+	{
+		Code:    UnclosedParenthesisCode,
+		Summary: "a grouping expression is missing its closing parenthesis",
+		Details: "Every '(' that opens a grouping expression must be matched by a ')' before the " +
+			"expression ends.",
+		Failing: "print (1 + 2;\n",
+		Fixed:   "print (1 + 2);\n",
+	},
+	{
+		Code:    ExpectedIdentifierCode,
+		Summary: "a name was expected",
+		Details: "Variable, function, parameter, class and property declarations need a name, and " +
+			"the name must be an identifier. Keywords such as 'class' or 'print' cannot be used " +
+			"as names.",
+		Failing: "var = 1;\n",
+		Fixed:   "var one = 1;\nprint one;\n",
+	},
+	{
+		Code:    BreakStatementOutsideLoopCode,
+		Summary: "a 'break' statement is not inside a loop",
+		Details: "'break' stops the execution of the innermost enclosing 'for' loop, so it can only " +
+			"be used inside the body of one.",
+		Failing: "if true {\n    break;\n}\n",
+		Fixed:   "for {\n    break;\n}\n",
+	},
+	{
+		Code:    ContinueStatementOutsideLoopCode,
+		Summary: "a 'continue' statement is not inside a loop",
+		Details: "'continue' skips to the next iteration of the innermost enclosing 'for' loop, so " +
+			"it can only be used inside the body of one.",
+		Failing: "if true {\n    continue;\n}\n",
+		Fixed:   "for var i = 0; i < 3; i = i + 1 {\n    if i == 1 {\n        continue;\n    }\n    print i;\n}\n",
+	},
+	{
+		Code:    ReturnStatementOutsideFunctionCode,
+		Summary: "a 'return' statement is not inside a function or method",
+		Details: "'return' finishes the execution of the function or method that contains it. " +
+			"Top level code and blocks cannot return.",
+		Failing: "if true {\n    return;\n}\n",
+		Fixed:   "fun greet() {\n    print \"hello\";\n    return;\n}\n\ngreet();\n",
+	},
+	{
+		Code:    ArgumentSizeExceededCode,
+		Summary: "a function has more than 255 parameters or arguments",
+		Details: "Functions and calls are limited to 255 parameters and arguments. Group the values " +
+			"in an object instead of passing them one by one.",
+		Failing: manyArguments(),
+		Fixed:   "fun sum(a, b, c) {\n    print a + b + c;\n}\n\nsum(1, 2, 3);\n",
+	},
+	{
+		Code:    InvalidTargetCode,
+		Summary: "the left side of an assignment cannot be assigned to",
+		Details: "Only variables and object properties can be assigned. Literals, calls and the " +
+			"result of other expressions are not valid assignment targets.",
+		Failing: "var a = 1;\na + 1 = 3;\n",
+		Fixed:   "var a = 1;\na = 3;\nprint a;\n",
+	},
+	{
+		Code:    InvalidSelfReferenceCode,
+		Summary: "a local variable is read in its own initializer",
+		Details: "A local variable does not exist until its initializer has been evaluated, so the " +
+			"initializer cannot reference the variable being declared. When shadowing an " +
+			"outer variable use a different name for the new one.",
+		Failing: "var count = count + 1;\nprint count;\n",
+		Fixed:   "var count = 1;\nvar next = count + 1;\nprint next;\n",
+	},
+	{
+		Code:    VariableAlreadyDeclaredCode,
+		Summary: "a variable is declared twice in the same scope",
+		Details: "Names must be unique within a scope. Assign the existing variable instead of " +
+			"declaring it again, or declare the second one in a nested block.",
+		Failing: "var name = \"lox\";\nvar name = \"golox\";\nprint name;\n",
+		Fixed:   "var name = \"lox\";\nname = \"golox\";\nprint name;\n",
+	},
+	{
+		Code:    UnusedVariableCode,
+		Summary: "a local variable, function or parameter is declared but never used",
+		Details: "Unused declarations are usually a sign of a mistake, such as a misspelled name, " +
+			"so they are rejected. Remove the declaration or use it.",
+		Failing: "var unused = 1;\n",
+		Fixed:   "var used = 1;\nprint used;\n",
+	},
+	{
+		Code:    ThisOutsideClassCode,
+		Summary: "'this' is used outside a class",
+		Details: "'this' refers to the instance a method was called on, so it only has a meaning " +
+			"inside the methods of a class.",
+		Failing: "print this;\n",
+		Fixed: "class Greeter {\n    greet() {\n        print this;\n    }\n}\n\n" +
+			"Greeter().greet();\n",
+	},
+	{
+		Code:    NoSelfInheritanceCode,
+		Summary: "a class inherits from itself",
+		Details: "A class cannot be its own superclass. Inherit from a different class or remove " +
+			"the superclass clause.",
+		Failing: "class Shape < Shape {}\nprint Shape;\n",
+		Fixed:   "class Base {}\nclass Shape < Base {}\nprint Shape;\n",
+	},
+	{
+		Code:    UndeclaredVariableCode,
+		Summary: "a variable is used but it is not declared anywhere in the program",
+		Details: "Every variable must be declared with 'var', 'fun' or 'class' before it can be " +
+			"used, unless it is a native function like 'clock'. The error suggests a similar " +
+			"name when there is one in scope, since the cause is usually a typo.",
+		Failing: "var count = 1;\nprint coutn;\n",
+		Fixed:   "var count = 1;\nprint count;\n",
+	},
+	{
+		Code:    InvalidDataTypeCode,
+		Summary: "an operator received a value of the wrong type",
+		Details: "Arithmetic operators work on numbers, '+' also works on strings, and comparison " +
+			"operators require both operands to be of the same type. Values are never " +
+			"converted implicitly.",
+		Failing: "print -\"one\";\n",
+		Fixed:   "print -1;\n",
+	},
+	{
+		Code:    InvalidOperationCode,
+		Summary: "the operation is not supported between the given types",
+		Details: "Some operators are only defined for specific types, for example '+' only adds " +
+			"numbers or concatenates strings and cannot be used with booleans or nil.",
+		Failing: "print true + 1;\n",
+		Fixed:   "print 1 + 1;\n",
+	},
+	{
+		Code:    DivisionByZeroCode,
+		Summary: "a number is divided by zero",
+		Details: "Division by zero is not supported. Check the divisor before dividing.",
+		Failing: "var divisor = 0;\nprint 10 / divisor;\n",
+		Fixed: "var divisor = 0;\nif divisor != 0 {\n    print 10 / divisor;\n} else {\n" +
+			"    print \"cannot divide by zero\";\n}\n",
+	},
+	{
+		Code:    UndefinedVariableCode,
+		Summary: "a variable is accessed before it is defined",
+		Details: "The variable is declared in the program but it was not defined yet when the " +
+			"code accessing it ran. This usually happens when a function uses a variable that " +
+			"is declared after the function is called.",
+		Failing: "fun show() {\n    print message;\n}\n\nshow();\nvar message = \"hi\";\nprint message;\n",
+		Fixed:   "fun show() {\n    print message;\n}\n\nvar message = \"hi\";\nshow();\nprint message;\n",
+	},
+	{
+		Code:    ExpressionIsNotCallableCode,
+		Summary: "a value that is not a function or a class is called",
+		Details: "Only functions, methods and classes can be called. Check that the callee is the " +
+			"value you expected.",
+		Failing: "var name = \"lox\";\nname();\n",
+		Fixed:   "fun name() {\n    print \"lox\";\n}\n\nname();\n",
+	},
+	{
+		Code:    WrongNumberOfArgumentsCode,
+		Summary: "a function is called with the wrong number of arguments",
+		Details: "The number of arguments of a call must match the number of parameters of the " +
+			"function, or of the 'init' method when instantiating a class.",
+		Failing: "fun add(a, b) {\n    print a + b;\n}\n\nadd(1);\n",
+		Fixed:   "fun add(a, b) {\n    print a + b;\n}\n\nadd(1, 2);\n",
+	},
+	{
+		Code:    NotAnObjectCode,
+		Summary: "a property is accessed on a value that is not an object",
+		Details: "Only instances have properties. Numbers, strings, booleans and nil do not.",
+		Failing: "var number = 1;\nprint number.value;\n",
+		Fixed: "class Box {\n    init(value) {\n        this.value = value;\n    }\n}\n\n" +
+			"var box = Box(1);\nprint box.value;\n",
+	},
+	{
+		Code:    InvalidPropertyCode,
+		Summary: "an instance does not have the accessed property or method",
+		Details: "Properties are created when they are first assigned, usually in 'init'. Reading " +
+			"a property that was never assigned, or calling a method the class does not define, " +
+			"raises this error.",
+		Failing: "class Point {\n    init() {\n        this.x = 1;\n    }\n}\n\n" +
+			"var point = Point();\nprint point.y;\n",
+		Fixed: "class Point {\n    init() {\n        this.x = 1;\n    }\n}\n\n" +
+			"var point = Point();\nprint point.x;\n",
+	},
+	{
+		Code:    NotAClassCode,
+		Summary: "a class inherits from a value that is not a class",
+		Details: "The superclass of a class must be another class.",
+		Failing: "var Base = \"base\";\nclass Derived < Base {}\nprint Derived;\n",
+		Fixed:   "class Base {}\nclass Derived < Base {}\nprint Derived;\n",
+	},
+	{
+		Code:    AssertionFailedCode,
+		Summary: "an assertion does not hold",
+		Details: "The natives 'assert', 'assertEqual' and 'assertThrows' raise this error when " +
+			"the condition is falsey, the values are different or the function does not raise " +
+			"an error. 'golox test' reports it as the failure of the test that made the assertion.",
+		Failing: "fun add(a, b) {\n    return a - b;\n}\n\nassertEqual(3, add(1, 2));\n",
+		Fixed:   "fun add(a, b) {\n    return a + b;\n}\n\nassertEqual(3, add(1, 2));\n",
+	},
+	{
+		Code:    StepLimitExceededCode,
+		Summary: "the program evaluated more statements and expressions than allowed",
+		Details: "Interpreters created with WithStepLimit count every statement, expression and " +
+			"loop iteration they evaluate, and stop the program at the next loop iteration or call once the " +
+			"count is over the limit. The usual cause is a loop whose condition never becomes false.",
+		Failing: "var i = 0;\nfor i < 10 {\n    print i;\n}\n",
+		Fixed:   "var i = 0;\nfor i < 10 {\n    print i;\n    i = i + 1;\n}\n",
+	},
+	{
+		Code:    DeadlineExceededCode,
+		Summary: "the program was still running at its deadline",
+		Details: "Interpreters created with WithDeadline check the time at every loop iteration " +
+			"and call, and stop the program once the deadline has passed.",
+		Failing: "for {\n}\n",
+		Fixed:   "for var i = 0; i < 10; i = i + 1 {\n}\n",
+	},
+	{
+		Code:    ExecutionCanceledCode,
+		Summary: "the context the program runs in was canceled",
+		Details: "Interpreters created with WithContext check the context at every loop iteration " +
+			"and call, and stop the program once it is done, either because it was canceled or " +
+			"because its deadline passed.",
+		Failing: "for {\n}\n",
+		Fixed:   "for var i = 0; i < 10; i = i + 1 {\n}\n",
+	},
+	{
+		Code:    StackOverflowCode,
+		Summary: "calls are nested deeper than the limit",
+		Details: "Every call made before the previous ones return nests deeper in the call stack, " +
+			"which is limited to 10000 calls unless the interpreter is created with " +
+			"WithDepthLimit. The usual cause is a recursive function that never reaches its base " +
+			"case. The error comes with the stack of calls, where the repeated ones are collapsed.",
+		Failing: "fun factorial(n) {\n    return n * factorial(n - 1);\n}\n\nprint factorial(5);\n",
+		Fixed:   "fun factorial(n) {\n    if n <= 1 {\n        return 1;\n    }\n    return n * factorial(n - 1);\n}\n\nprint factorial(5);\n",
+	},
+	{
+		Code:    MemoryLimitExceededCode,
+		Summary: "the program allocated more memory than allowed",
+		Details: "Interpreters created with WithMemoryLimit or WithObjectLimit account for the " +
+			"strings built by concatenation, the instances, the functions and the environments " +
+			"the program allocates, and stop it once the total is over the limit. Memory is never " +
+			"given back to the quota, so long running programs must keep what they allocate small.",
+		Failing: "var text = \"lox\";\nfor {\n    text = text + text;\n}\n",
+		Fixed:   "var text = \"lox\";\nfor var i = 0; i < 3; i = i + 1 {\n    text = text + text;\n}\nprint text;\n",
+	},
+	{
+		Code:    PermissionDeniedCode,
+		Summary: "a native needs a capability the script was not granted",
+		Details: "Natives that reach outside of the interpreter, to read the time, generate random " +
+			"numbers, read the environment or access files, need the 'clock', 'random', 'env', " +
+			"'fs:read' and 'fs:write' capabilities. The command line grants all of them, programs " +
+			"embedding the interpreter grant them with WithCapabilities, and the file system ones " +
+			"can be restricted to a directory, as 'fs:read:/data'. Grant the capability, or " +
+			"give the script the value the native would read instead of calling it.",
+		Failing: "fun stamp(label, seconds) {\n    print label + \" at \" + str(seconds);\n}\n\nstamp(\"build\", clock());\n",
+		Fixed:   "fun stamp(label, seconds) {\n    print label + \" at \" + str(seconds);\n}\n\nstamp(\"build\", 1700000000);\n",
+	},
+	{
+		Code:    InvalidIndexCode,
+		Summary: "a position is outside of a string or a list",
+		Details: "Positions of strings and lists start at 0 and must be integers smaller than their " +
+			"length, which strings count in characters. Check the length with 'len()' before " +
+			"reading a position.",
+		Failing: "var name = \"lox\";\nprint name.at(3);\n",
+		Fixed:   "var name = \"lox\";\nprint name.at(name.len() - 1);\n",
+	},
+	{
+		Code:    InvalidNumberCode,
+		Summary: "a string converted to a number does not hold one",
+		Details: "'num' parses decimal numbers, as \"42\" or \" -1.5e3 \", surrounding spaces are " +
+			"ignored. Any other character in the string makes the conversion fail.",
+		Failing: "print num(\"42 apples\");\n",
+		Fixed:   "print num(\"42 apples\".split(\" \").get(0));\n",
+	},
+	{
+		Code:    InvalidFormatCode,
+		Summary: "the layout of format or printf does not match its arguments",
+		Details: "Every verb of the layout, as %d or %-8.2f, takes one argument in order and every " +
+			"argument needs a verb. %d and the other integer verbs only take whole numbers, use " +
+			"%f or %v for any other. A literal percent sign is written %%.",
+		Failing: "print format(\"%d%\", 99.5);\n",
+		Fixed:   "print format(\"%.1f%%\", 99.5);\n",
+	},
+	{
+		Code:    FileErrorCode,
+		Summary: "the file system failed an operation of the fs namespace",
+		Details: "Files that don't exist, directories that aren't empty, missing permissions of the " +
+			"operating system and file handles used after they were closed make the operations " +
+			"fail. Relative paths are resolved against the root directory when there is one, or " +
+			"the working directory otherwise.",
+		Failing: "print fs.readFile(\"missing.txt\");\n",
+		Fixed:   "if fs.exists(\"missing.txt\") {\n    print fs.readFile(\"missing.txt\");\n}\n",
+	},
+	{
+		Code:    InvalidJSONCode,
+		Summary: "a text is not JSON, or a value can't be written as JSON",
+		Details: "json.parse needs a single JSON value, with its strings and keys between double " +
+			"quotes. json.stringify writes nil, booleans, numbers, strings, lists and the " +
+			"properties of instances, but not functions, classes, infinite numbers or values " +
+			"that contain themselves.",
+		Failing: "class Node {}\nvar node = Node();\nnode.next = node;\nprint json.stringify(node);\n",
+		Fixed:   "class Node {}\nvar node = Node();\nnode.next = nil;\nprint json.stringify(node);\n",
+	},
+	{
+		Code:    InvalidTimeCode,
+		Summary: "a date, a duration or a time zone is not valid",
+		Details: "Layouts of time.parse and format are written as Go's reference time, " +
+			"Mon Jan 2 15:04:05 MST 2006, and the text must follow them exactly. Durations are " +
+			"written as \"1h30m\" or \"250ms\", time zones are names of the tz database, as " +
+			"\"Europe/Madrid\", and dates and durations must be within about 292 years.",
+		Failing: "print time.parse(time.DateOnly, \"31/12/2024\");\n",
+		Fixed:   "print time.parse(\"02/01/2006\", \"31/12/2024\");\n",
+	},
+}
@@ -0,0 +1,81 @@
+package lox_test
+
+import (
+	"context"
+	"golox/lox"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+type coded interface {
+	Code() string
+}
+
+// limited are the options that stop the failing examples of the execution limits, which would run
+// forever otherwise
+func limited() map[string][]lox.Option {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	return map[string][]lox.Option{
+		lox.StepLimitExceededCode:   {lox.WithStepLimit(1000)},
+		lox.DeadlineExceededCode:    {lox.WithDeadline(time.Now())},
+		lox.ExecutionCanceledCode:   {lox.WithContext(canceled)},
+		lox.MemoryLimitExceededCode: {lox.WithMemoryLimit(1 << 20)},
+		lox.PermissionDeniedCode:    {},
+		lox.FileErrorCode:           {lox.WithCapabilities(lox.FSReadCapability)},
+	}
+}
+
+func run(source string, options ...lox.Option) error {
+	tokens, errs := lox.NewScanner(source).ScanTokens()
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	stmts, errs := lox.NewParser(tokens).Parse()
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	interpreter := lox.NewInterpreter(append(options, lox.WithOutput(ioutil.Discard))...)
+	if _, err := lox.NewResolver(interpreter).Resolve(stmts); err != nil {
+		return err
+	}
+
+	return interpreter.Interpret(stmts)
+}
+
+func TestExplanations(t *testing.T) {
+	limits := limited()
+	for _, e := range lox.Explanations() {
+		e := e
+		t.Run(e.Code, func(t *testing.T) {
+			err := run(e.Failing, limits[e.Code]...)
+			if err == nil {
+				t.Fatalf("failing example did not raise an error")
+			}
+
+			c, ok := err.(coded)
+			if !ok || c.Code() != e.Code {
+				t.Fatalf("failing example raised %q", err)
+			}
+
+			if err := run(e.Fixed, lox.WithCapabilities(lox.AllCapabilities()...)); err != nil {
+				t.Fatalf("fixed example raised %q", err)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	e, ok := lox.Explain("unusedvariable")
+	if !ok || e.Code != lox.UnusedVariableCode {
+		t.Fatalf("expected the explanation of %s", lox.UnusedVariableCode)
+	}
+
+	if _, ok := lox.Explain("NotACode"); ok {
+		t.Fatalf("unexpected explanation")
+	}
+}
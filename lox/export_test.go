@@ -0,0 +1,7 @@
+package lox
+
+// Unexported helpers the tests of lox_test exercise directly
+var (
+	EditDistance = editDistance
+	ClosestName  = closestName
+)
@@ -0,0 +1,171 @@
+package lox
+
+import (
+	"strings"
+)
+
+const indentation = "    "
+
+// Format returns the canonical formatting of the given source. Sources with lexical or syntax
+// errors are not formatted, the first error found is returned instead.
+//
+// The formatter works on the token stream, comments included, so everything but the whitespace
+// between tokens is preserved: statements go in their own line, blocks are indented, binary
+// operators are surrounded by spaces and at most one blank line is kept between statements.
+func Format(source string) (string, error) {
+	tokens, errs := NewScanner(source).ScanTokens()
+	if len(errs) > 0 {
+		return "", errs[0]
+	}
+
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	if _, errs := NewParser(tokens).Parse(); len(errs) > 0 {
+		return "", errs[0]
+	}
+
+	tokens, _ = NewScanner(source).WithComments().ScanTokens()
+
+	f := &formatter{}
+	for i, t := range tokens {
+		if t.Is(EOF) {
+			break
+		}
+		f.format(t, tokens[i+1])
+	}
+
+	out := f.b.String()
+	if out == "" {
+		return "", nil
+	}
+
+	return out + "\n", nil
+}
+
+type formatter struct {
+	b      strings.Builder
+	indent int
+	prev   *Token
+	// prevUnary is true when the previous token is a unary operator
+	prevUnary bool
+	// prevEndLine is the line where the previous token ends
+	prevEndLine int
+	// newline is true when a line break must be written before the next token
+	newline bool
+	parens  int
+	// forHeader is true while formatting the clauses of a for statement, forParens is the
+	// parenthesis depth of its for keyword
+	forHeader bool
+	forParens int
+}
+
+func (f *formatter) format(t *Token, next *Token) {
+	if t.Is(COMMENT) {
+		f.comment(t)
+		return
+	}
+
+	if t.Is(RIGHT_BRACE) && !f.prev.Is(LEFT_BRACE) {
+		f.indent--
+		f.newline = true
+	}
+
+	if f.newline {
+		f.lineBreak(t)
+	} else if f.spaceBefore(t) {
+		f.b.WriteString(" ")
+	}
+
+	f.b.WriteString(t.lexeme)
+
+	unary := false
+	switch t.tokenType {
+	case LEFT_PAREN:
+		f.parens++
+	case RIGHT_PAREN:
+		f.parens--
+	case FOR:
+		f.forHeader = true
+		f.forParens = f.parens
+	case LEFT_BRACE:
+		if f.forHeader && f.parens == f.forParens {
+			f.forHeader = false
+		}
+		if !next.Is(RIGHT_BRACE) {
+			f.indent++
+			f.newline = true
+		}
+	case RIGHT_BRACE:
+		f.newline = !next.OneOf(ELSE, SEMICOLON, RIGHT_PAREN, COMMA, DOT)
+	case SEMICOLON:
+		f.newline = !f.forHeader
+	case BANG:
+		unary = true
+	case MINUS:
+		unary = !f.isOperand(f.prev)
+	}
+
+	f.prev = t
+	f.prevUnary = unary
+	f.prevEndLine = endLine(t)
+}
+
+// comment writes a comment at the end of the current line when it was there in the source,
+// or in its own line otherwise
+func (f *formatter) comment(t *Token) {
+	if f.b.Len() > 0 && t.line == f.prevEndLine {
+		f.b.WriteString(" ")
+	} else if f.b.Len() > 0 {
+		f.lineBreak(t)
+	}
+
+	f.b.WriteString(t.lexeme)
+	f.newline = true
+	f.prevEndLine = endLine(t)
+}
+
+// lineBreak ends the current line, keeping a blank line if there was at least one in the source,
+// and indents the next one
+func (f *formatter) lineBreak(t *Token) {
+	f.b.WriteString("\n")
+	afterBrace := f.prev != nil && f.prev.Is(LEFT_BRACE)
+	if t.line > f.prevEndLine+1 && !afterBrace && !t.Is(RIGHT_BRACE) {
+		f.b.WriteString("\n")
+	}
+
+	f.b.WriteString(strings.Repeat(indentation, f.indent))
+	f.newline = false
+}
+
+func (f *formatter) spaceBefore(t *Token) bool {
+	if f.prev == nil || f.prevUnary {
+		return false
+	}
+
+	if t.OneOf(SEMICOLON, COMMA, DOT, RIGHT_PAREN) || f.prev.OneOf(LEFT_PAREN, DOT, INTERPOLATION) {
+		return false
+	}
+
+	// The rest of an interpolated string follows its expression
+	if t.OneOf(INTERPOLATION, STRING) && strings.HasPrefix(t.lexeme, "}") {
+		return false
+	}
+
+	if t.Is(LEFT_PAREN) && f.prev.OneOf(IDENTIFIER, RIGHT_PAREN, FUN) {
+		return false
+	}
+
+	return !(t.Is(RIGHT_BRACE) && f.prev.Is(LEFT_BRACE))
+}
+
+// isOperand returns true when the token ends an operand, which makes a following minus a binary
+// operator instead of a unary one
+func (f *formatter) isOperand(t *Token) bool {
+	return t != nil && t.OneOf(IDENTIFIER, NUMBER, STRING, TRUE, FALSE, NIL, THIS, RIGHT_PAREN)
+}
+
+func endLine(t *Token) int {
+	return t.line + strings.Count(t.lexeme, "\n")
+}
@@ -0,0 +1,68 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	source := `// Shapes
+class Square<Shape{
+  init(side){this.side=side;}  // the side
+
+
+  area(){return this.side*this.side;}
+}
+var negate=fun(a){return -a;};
+for var i=0;i<3;i=i+1{
+  if i==1{continue;}else{print negate(i)+-1;}
+}
+for{break;}
+/* done */`
+
+	expected := `// Shapes
+class Square < Shape {
+    init(side) {
+        this.side = side;
+    } // the side
+
+    area() {
+        return this.side * this.side;
+    }
+}
+var negate = fun(a) {
+    return -a;
+};
+for var i = 0; i < 3; i = i + 1 {
+    if i == 1 {
+        continue;
+    } else {
+        print negate(i) + -1;
+    }
+}
+for {
+    break;
+}
+/* done */
+`
+
+	res, err := lox.Format(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, res)
+	}
+
+	again, err := lox.Format(res)
+	if err != nil || again != res {
+		t.Fatalf("formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	if _, err := lox.Format("print 1"); err == nil {
+		t.Fatal("expected a syntax error")
+	}
+}
@@ -0,0 +1,201 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scriptFrame is the name of the frame of the top level code
+const scriptFrame = "script"
+
+// Frame of the call stack of the interpreter
+type Frame struct {
+	// Name of the function being executed
+	Name string
+	// Declaration is the position of the name of the function, zero for the script and lambdas
+	Declaration Position
+	// Position of the statement being executed, zero until the first one starts
+	Position Position
+	// Call is the position of the call that pushed the frame, zero for the script
+	Call Position
+	// environment of the statement being executed
+	environment *Environment
+}
+
+func (i *Interpreter) frame() *Frame {
+	return i.frames[len(i.frames)-1]
+}
+
+// pushFrame for a call to the function declared with the given name, paren is the closing
+// parenthesis of the call
+func (i *Interpreter) pushFrame(name string, declaration, paren *Token) {
+	f := &Frame{Name: name, environment: i.environment}
+	if declaration != nil {
+		f.Declaration = position(declaration)
+	}
+	if paren != nil {
+		f.Call = position(paren)
+	}
+
+	if i.profiler != nil {
+		i.profiler.enter(i.frames, f)
+	}
+	i.frames = append(i.frames, f)
+}
+
+// stack is a copy of the call stack, the innermost frame first
+func (i *Interpreter) stack() []*Frame {
+	stack := make([]*Frame, len(i.frames))
+	for n, f := range i.frames {
+		frame := *f
+		stack[len(i.frames)-1-n] = &frame
+	}
+	return stack
+}
+
+// maxCycle is the longest run of frames collapsed when it repeats, as the calls of a mutual
+// recursion
+const maxCycle = 16
+
+// traceEdge is the number of lines kept at each end of a trace that is still too long once its
+// cycles are collapsed
+const traceEdge = 20
+
+// traceLine of a stack trace and the number of frames it stands for
+type traceLine struct {
+	text   string
+	frames int
+}
+
+// StackTrace renders a call stack one frame per line, the innermost first. Runs of frames that
+// repeat, as the ones of a recursion or a mutual recursion, are collapsed, and only both ends of
+// traces that are still too long are kept.
+func StackTrace(stack []*Frame) string {
+	var lines []traceLine
+	for n := 0; n < len(stack); {
+		period, repeats := cycle(stack[n:])
+		for _, f := range stack[n : n+period] {
+			lines = append(lines, traceLine{fmt.Sprintf("    at %s (%d:%d)", f.Name, f.Position.Line, f.Position.Column), 1})
+		}
+
+		if repeats > 0 && period == 1 {
+			lines = append(lines, traceLine{fmt.Sprintf("    ... repeated %d more times", repeats), repeats})
+		} else if repeats > 0 {
+			lines = append(lines, traceLine{fmt.Sprintf("    ... previous %d frames repeated %d more times", period, repeats), period * repeats})
+		}
+		n += period * (repeats + 1)
+	}
+
+	if len(lines) > 2*traceEdge+1 {
+		omitted := 0
+		for _, l := range lines[traceEdge : len(lines)-traceEdge] {
+			omitted += l.frames
+		}
+
+		tail := lines[len(lines)-traceEdge:]
+		lines = append(lines[:traceEdge:traceEdge], traceLine{fmt.Sprintf("    ... %d more frames", omitted), omitted})
+		lines = append(lines, tail...)
+	}
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// cycle returns the length of the run of frames at the top of the stack that repeats right after
+// itself the most, and how many more times it does. The run that collapses more frames wins, a
+// frame that does not repeat is a run of 1 with no repetitions.
+func cycle(stack []*Frame) (int, int) {
+	period, repeats := 1, 0
+	for p := 1; p <= maxCycle && 2*p <= len(stack); p++ {
+		r := 0
+		for start := p; start+p <= len(stack) && sameFrames(stack[:p], stack[start:start+p]); start += p {
+			r++
+		}
+
+		if p*r > period*repeats {
+			period, repeats = p, r
+		}
+	}
+	return period, repeats
+}
+
+// sameFrames tells whether two runs of frames of the same length are at the same positions
+func sameFrames(a, b []*Frame) bool {
+	for n := range a {
+		if !sameFrame(a[n], b[n]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameFrame(a, b *Frame) bool {
+	return a.Name == b.Name && a.Declaration == b.Declaration && a.Position == b.Position
+}
+
+func (i *Interpreter) popFrame() {
+	if i.profiler != nil {
+		i.profiler.exit(i.frames)
+	}
+	i.frames = i.frames[:len(i.frames)-1]
+}
+
+// statementPosition returns the position of the first token of a statement. Blocks have no
+// position of their own, the positions of their statements are used instead.
+func statementPosition(s Stmt) (Position, bool) {
+	var t *Token
+	switch s := s.(type) {
+	case *VarStmt:
+		t = s.name
+	case *PrintStmt:
+		t = s.keyword
+	case *IfStmt:
+		t = s.keyword
+	case *ForStmt:
+		t = s.keyword
+	case *CircuitBreakStmt:
+		t = s.keyword
+	case *FunctionStmt:
+		t = s.name
+	case *ClassStmt:
+		t = s.name
+	case *ExpressionStmt:
+		t = firstToken(s.expression)
+	}
+
+	if t == nil {
+		return Position{}, false
+	}
+	return position(t), true
+}
+
+// firstToken of an expression, nil when it starts with a literal or a parenthesis
+func firstToken(e Expression) *Token {
+	switch e := e.(type) {
+	case *Variable:
+		return e.token
+	case *Assign:
+		return e.name
+	case *This:
+		return e.keyword
+	case *Unary:
+		return e.operator
+	case *Binary:
+		return firstToken(e.left)
+	case *Logical:
+		return firstToken(e.left)
+	case *Call:
+		return firstToken(e.callee)
+	case *Get:
+		return firstToken(e.object)
+	case *Set:
+		return firstToken(e.object)
+	case *Interpolation:
+		return e.quote
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	a := &lox.Frame{Name: "a", Position: lox.Position{Line: 2, Column: 5}}
+	b := &lox.Frame{Name: "b", Position: lox.Position{Line: 6, Column: 5}}
+	script := &lox.Frame{Name: "script", Position: lox.Position{Line: 9, Column: 1}}
+
+	tests := map[string]struct {
+		stack    []*lox.Frame
+		expected string
+	}{
+		"recursion": {
+			stack:    []*lox.Frame{a, a, a, script},
+			expected: "    at a (2:5)\n    ... repeated 2 more times\n    at script (9:1)\n",
+		},
+		"mutual recursion": {
+			stack:    []*lox.Frame{b, a, b, a, b, a, script},
+			expected: "    at b (6:5)\n    at a (2:5)\n    ... previous 2 frames repeated 2 more times\n    at script (9:1)\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if trace := lox.StackTrace(test.stack); trace != test.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.expected, trace)
+			}
+		})
+	}
+}
+
+func TestStackTraceTooLong(t *testing.T) {
+	// Frames at different positions don't repeat, only both ends of the trace are kept
+	var stack []*lox.Frame
+	for n := 1; n <= 10000; n++ {
+		stack = append(stack, &lox.Frame{Name: "f", Position: lox.Position{Line: n, Column: 1}})
+	}
+
+	lines := strings.Split(strings.TrimSuffix(lox.StackTrace(stack), "\n"), "\n")
+	if len(lines) != 41 {
+		t.Fatalf("expected 41 lines, got %d", len(lines))
+	}
+	if lines[20] != "    ... 9960 more frames" {
+		t.Fatalf("expected the omitted frames to be counted, got %q", lines[20])
+	}
+	if lines[40] != "    at f (10000:1)" {
+		t.Fatalf("expected the outermost frame last, got %q", lines[40])
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	countdown := `fun countdown(n) {
+    if n > 0 {
+        countdown(n - 1);
+    }
+}
+countdown(50);
+`
+	forever := `fun forever() {
+    forever();
+}
+forever();
+`
+
+	tests := map[string]struct {
+		source   string
+		limit    int
+		overflow bool
+	}{
+		"within the limit":           {countdown, 100, false},
+		"over the limit":             {countdown, 10, true},
+		"zero keeps the default":     {countdown, 0, false},
+		"zero still stops recursion": {forever, 0, true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interpret(t, test.source, lox.WithDepthLimit(test.limit))
+			if !test.overflow {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.StackOverflowCode {
+				t.Fatalf("expected %s, got %v", lox.StackOverflowCode, err)
+			}
+		})
+	}
+}
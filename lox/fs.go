@@ -0,0 +1,326 @@
+package lox
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// fileSize is the estimated size of a file handle
+const fileSize = 64
+
+// NewFSNamespace constructor of the fs namespace. Reading needs the fs:read capability and
+// anything that changes the file system needs fs:write, restricted to the root of the
+// interpreter when it has one.
+func NewFSNamespace() *Namespace {
+	return NewNamespace("fs", map[string]interface{}{
+		"readFile": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			path, err := i.fsPath(paren, FSReadCapability, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := i.readFile(paren, arguments[0].(string), path)
+			if err != nil {
+				return nil, err
+			}
+			return i.newString(content), nil
+		}),
+		"writeFile": NewNativeFunction(2, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			return nil, i.writeFile(paren, "write", arguments, os.O_TRUNC)
+		}),
+		"appendFile": NewNativeFunction(2, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			return nil, i.writeFile(paren, "append to", arguments, os.O_APPEND)
+		}),
+		"exists": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			path, err := i.fsPath(paren, FSReadCapability, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = os.Stat(path)
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			if err != nil {
+				return nil, FileError(paren, "check", arguments[0].(string), err)
+			}
+			return true, nil
+		}),
+		"listDir": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// listDir returns the names of the entries of a directory, sorted
+			path, err := i.fsPath(paren, FSReadCapability, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, FileError(paren, "list", arguments[0].(string), err)
+			}
+
+			names := make([]interface{}, len(entries))
+			for n, entry := range entries {
+				names[n] = i.newString(entry.Name())
+			}
+			sort.Slice(names, func(a, b int) bool {
+				return names[a].(string) < names[b].(string)
+			})
+			return i.newList(names), nil
+		}),
+		"remove": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// remove a file or an empty directory
+			path, err := i.fsPath(paren, FSWriteCapability, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if err := os.Remove(path); err != nil {
+				return nil, FileError(paren, "remove", arguments[0].(string), err)
+			}
+			return nil, nil
+		}),
+		"mkdir": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// mkdir creates a directory along with the parents it is missing
+			path, err := i.fsPath(paren, FSWriteCapability, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return nil, FileError(paren, "create", arguments[0].(string), err)
+			}
+			return nil, nil
+		}),
+		"open": NewNativeFunction(1, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// open a file to read it, with the "r" mode which is the default, to write it from
+			// scratch with "w" or to append to it with "a"
+			if len(arguments) > 2 {
+				return nil, WrongNumberOfArguments(paren, len(arguments), 2)
+			}
+
+			mode := "r"
+			if len(arguments) == 2 {
+				var err error
+				if mode, err = stringArgument(paren, arguments[1]); err != nil {
+					return nil, err
+				}
+			}
+			return i.open(paren, arguments[0], mode)
+		}),
+	})
+}
+
+// fsPath returns the absolute path given as argument once the script is allowed to access it
+func (i *Interpreter) fsPath(paren *Token, c Capability, argument interface{}) (string, error) {
+	path, err := stringArgument(paren, argument)
+	if err != nil {
+		return "", err
+	}
+	return i.requirePath(paren, c, path)
+}
+
+// readChunk is the size of the pieces files are read in, the memory limit is checked after each
+const readChunk = 32 << 10
+
+// readFile checks its size against the memory limit before reading it, and then every piece it
+// reads, since files such as devices and pipes are larger than their size tells. The name is the
+// path the script gave.
+func (i *Interpreter) readFile(paren *Token, name, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", FileError(paren, "read", name, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", FileError(paren, "read", name, err)
+	}
+	if !i.fits(int(info.Size())) {
+		return "", MemoryLimitExceeded(paren, "bytes", i.memoryLimit)
+	}
+
+	var b strings.Builder
+	b.Grow(int(info.Size()))
+	chunk := make([]byte, readChunk)
+	for {
+		n, err := f.Read(chunk)
+		b.Write(chunk[:n])
+		if !i.fits(b.Len()) {
+			return "", MemoryLimitExceeded(paren, "bytes", i.memoryLimit)
+		}
+
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", FileError(paren, "read", name, err)
+		}
+	}
+}
+
+// writeFile with the content given as second argument, creating it when it does not exist
+func (i *Interpreter) writeFile(paren *Token, operation string, arguments []interface{}, flag int) error {
+	path, err := i.fsPath(paren, FSWriteCapability, arguments[0])
+	if err != nil {
+		return err
+	}
+
+	content, err := stringArgument(paren, arguments[1])
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|flag, 0644)
+	if err != nil {
+		return FileError(paren, operation, arguments[0].(string), err)
+	}
+
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return FileError(paren, operation, arguments[0].(string), err)
+	}
+	return nil
+}
+
+// open a file handle with one of the modes of fs.open
+func (i *Interpreter) open(paren *Token, argument interface{}, mode string) (*File, error) {
+	flags := map[string]int{
+		"r": os.O_RDONLY,
+		"w": os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+		"a": os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	}
+
+	flag, ok := flags[mode]
+	if !ok {
+		return nil, FileError(paren, "open", Stringify(argument), errors.New("invalid mode "+quote(mode)+", expecting \"r\", \"w\" or \"a\""))
+	}
+
+	c := FSWriteCapability
+	if mode == "r" {
+		c = FSReadCapability
+	}
+
+	path, err := i.fsPath(paren, c, argument)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, FileError(paren, "open", argument.(string), err)
+	}
+
+	i.allocate(fileSize)
+	handle := &File{name: argument.(string), file: f, reader: bufio.NewReader(f)}
+	if i.files == nil {
+		i.files = map[*File]bool{}
+	}
+	i.files[handle] = true
+	return handle, nil
+}
+
+// closeFile closes a handle and forgets it
+func (i *Interpreter) closeFile(f *File) error {
+	f.closed = true
+	delete(i.files, f)
+	return f.file.Close()
+}
+
+// Close the file handles the script left open. Handles stay open between calls to Interpret, so
+// programs embedding the interpreter call Close once they are done with it.
+func (i *Interpreter) Close() error {
+	var first error
+	for f := range i.files {
+		if err := i.closeFile(f); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// File handle opened by fs.open, it reads and writes a file a piece at a time. Handles are closed
+// by scripts, or by Interpreter.Close the ones they forget, once closed all their methods fail.
+type File struct {
+	name   string
+	file   *os.File
+	reader *bufio.Reader
+	closed bool
+}
+
+func (f *File) String() string {
+	return "<file " + f.name + ">"
+}
+
+// fileMethods are called on file handles, as f.readLine()
+var fileMethods = map[string]func(f *File) *NativeFunction{
+	"readLine": func(f *File) *NativeFunction {
+		// readLine returns the next line without its line break, nil at the end of the file
+		return NewNativeFunction(0, false, func(i *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+			if f.closed {
+				return nil, FileError(paren, "read", f.name, os.ErrClosed)
+			}
+
+			// The line is read a buffer at a time so a line longer than the memory left fails
+			// before it is read whole
+			var line []byte
+			for {
+				chunk, err := f.reader.ReadSlice('\n')
+				line = append(line, chunk...)
+				if !i.fits(len(line)) {
+					return nil, MemoryLimitExceeded(paren, "bytes", i.memoryLimit)
+				}
+
+				if err == bufio.ErrBufferFull {
+					continue
+				}
+				if err == io.EOF && len(line) == 0 {
+					return nil, nil
+				}
+				if err != nil && err != io.EOF {
+					return nil, FileError(paren, "read", f.name, err)
+				}
+				break
+			}
+
+			s := strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r")
+			return i.newString(s), nil
+		})
+	},
+	"write": func(f *File) *NativeFunction {
+		return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			content, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if f.closed {
+				return nil, FileError(paren, "write", f.name, os.ErrClosed)
+			}
+
+			if _, err := f.file.WriteString(content); err != nil {
+				return nil, FileError(paren, "write", f.name, err)
+			}
+			return nil, nil
+		})
+	},
+	"close": func(f *File) *NativeFunction {
+		return NewNativeFunction(0, false, func(i *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+			if f.closed {
+				return nil, FileError(paren, "close", f.name, os.ErrClosed)
+			}
+
+			if err := i.closeFile(f); err != nil {
+				return nil, FileError(paren, "close", f.name, err)
+			}
+			return nil, nil
+		})
+	},
+}
@@ -0,0 +1,124 @@
+package lox_test
+
+import (
+	"bytes"
+	"golox/lox"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	root := t.TempDir()
+	out, err := interpret(t, `fs.mkdir("reports");
+fs.writeFile("reports/a.txt", "one\n");
+fs.appendFile("reports/a.txt", "two\n");
+print fs.readFile("reports/a.txt").len();
+print fs.exists("reports/b.txt");
+
+var f = fs.open("reports/b.txt", "w");
+f.write("x\r\ny");
+f.close();
+
+f = fs.open("reports/b.txt");
+for var line = f.readLine(); line; line = f.readLine() {
+    print line;
+}
+f.close();
+print fs.listDir("reports");
+
+fs.remove("reports/a.txt");
+print fs.listDir("reports");
+`, lox.WithCapabilities(lox.AllCapabilities()...), lox.WithRoot(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "8\nfalse\nx\ny\n[\"a.txt\", \"b.txt\"]\n[\"b.txt\"]\n"
+	if out != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "reports", "b.txt")); err != nil {
+		t.Fatalf("expected the file to be written within the root: %v", err)
+	}
+}
+
+func TestFSRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skip("symbolic links are not supported:", err)
+	}
+
+	tests := map[string]struct {
+		source       string
+		capabilities []lox.Capability
+	}{
+		"parent":        {`fs.readFile("../secret");`, lox.AllCapabilities()},
+		"absolute":      {`fs.readFile("` + filepath.ToSlash(outside) + `/secret");`, lox.AllCapabilities()},
+		"link":          {`fs.writeFile("link/secret", "");`, lox.AllCapabilities()},
+		"no capability": {`fs.exists("a");`, nil},
+		"read only":     {`fs.mkdir("a");`, []lox.Capability{lox.FSReadCapability}},
+		"other dir":     {`fs.exists("a");`, []lox.Capability{lox.Capability("fs:read:" + outside)}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interpret(t, test.source, lox.WithCapabilities(test.capabilities...), lox.WithRoot(root))
+			if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.PermissionDeniedCode {
+				t.Fatalf("expected %s, got %v", lox.PermissionDeniedCode, err)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "secret")); err == nil {
+		t.Fatal("expected nothing to be written outside of the root")
+	}
+}
+
+func TestFSWithinMemoryLimit(t *testing.T) {
+	root := t.TempDir()
+	line := bytes.Repeat([]byte("x"), 4<<20)
+	if err := os.WriteFile(filepath.Join(root, "big.txt"), line, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"readFile": `fs.readFile("big.txt");`,
+		"readLine": `var f = fs.open("big.txt");
+f.readLine();`,
+	}
+
+	for name, source := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interpret(t, source, lox.WithCapabilities(lox.FSReadCapability), lox.WithRoot(root), lox.WithMemoryLimit(1<<20))
+			if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.MemoryLimitExceededCode {
+				t.Fatalf("expected %s, got %v", lox.MemoryLimitExceededCode, err)
+			}
+		})
+	}
+}
+
+func TestFSCloseLeftOpen(t *testing.T) {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("open file descriptors can't be counted:", err)
+	}
+
+	stmts := parse(t, `var f = fs.open("a.txt", "w");
+f.write("a");
+var g = fs.open("a.txt");
+g.close();`)
+	i := resolve(t, stmts, lox.WithCapabilities(lox.AllCapabilities()...), lox.WithRoot(t.TempDir()))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if left, _ := os.ReadDir("/proc/self/fd"); len(left) != len(fds) {
+		t.Fatalf("expected %d open file descriptors, got %d", len(fds), len(left))
+	}
+}
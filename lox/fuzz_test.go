@@ -0,0 +1,89 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// addSeeds adds the conformance programs and the examples to the corpus of a fuzz target
+func addSeeds(f *testing.F) {
+	for _, pattern := range []string{"testdata/*/*.lox", "../examples/*.lox"} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(string(b))
+		}
+	}
+}
+
+func FuzzScanner(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, source string) {
+		lox.NewScanner(source).ScanTokens()
+	})
+}
+
+func FuzzParser(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, _ := lox.NewScanner(source).ScanTokens()
+		if len(tokens) > 0 {
+			lox.NewParser(tokens).Parse()
+		}
+	})
+}
+
+func FuzzResolver(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, source string) {
+		stmts, ok := fuzzParse(source)
+		if ok {
+			lox.NewResolver(lox.NewInterpreter()).Resolve(stmts)
+		}
+		lox.Analyze(source)
+	})
+}
+
+func FuzzInterpreter(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, source string) {
+		stmts, ok := fuzzParse(source)
+		if !ok {
+			return
+		}
+
+		// Programs may loop forever or allocate without bounds
+		i := lox.NewInterpreter(
+			lox.WithOutput(ioutil.Discard),
+			lox.WithStepLimit(100000),
+			lox.WithMemoryLimit(64<<20),
+			lox.WithDeadline(time.Now().Add(100*time.Millisecond)),
+		)
+		if _, err := lox.NewResolver(i).Resolve(stmts); err != nil {
+			return
+		}
+		i.Interpret(stmts)
+	})
+}
+
+// fuzzParse parses a source, it is not ok when the source has errors
+func fuzzParse(source string) ([]lox.Stmt, bool) {
+	tokens, errs := lox.NewScanner(source).ScanTokens()
+	if len(errs) > 0 || len(tokens) == 0 {
+		return nil, false
+	}
+
+	stmts, errs := lox.NewParser(tokens).Parse()
+	return stmts, len(errs) == 0
+}
@@ -1,11 +1,13 @@
 package lox
 
-import "fmt"
-
 func isDigit(v rune) bool {
 	return v >= '0' && v <= '9'
 }
 
+func isHexDigit(v rune) bool {
+	return isDigit(v) || v >= 'a' && v <= 'f' || v >= 'A' && v <= 'F'
+}
+
 func isAlpha(v rune) bool {
 	return v >= 'a' && v <= 'z' || v >= 'A' && v <= 'Z' || v == '_'
 }
@@ -14,8 +16,65 @@ func isAlphanumeric(v rune) bool {
 	return isAlpha(v) || isDigit(v)
 }
 
-func report(line int, where, message string) {
-	fmt.Printf("[line %v] Error %s: %s", line, where, message)
+// editDistance between two strings counting insertions, deletions, substitutions and transpositions
+// of adjacent characters, used to suggest similar names
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = d[i-1][j] + 1
+			if d[i][j-1]+1 < d[i][j] {
+				d[i][j] = d[i][j-1] + 1
+			}
+			if d[i-1][j-1]+cost < d[i][j] {
+				d[i][j] = d[i-1][j-1] + cost
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// closestName returns the candidate with the smallest edit distance to name. Candidates that
+// are too far away to be a plausible typo are discarded, in which case it returns an empty string.
+func closestName(name string, candidates []string) string {
+	threshold := len([]rune(name)) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	best := ""
+	bestDistance := threshold + 1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+
+		d := editDistance(name, candidate)
+		if d < bestDistance || d == bestDistance && candidate < best {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best
 }
 
 func isTruthy(v interface{}) bool {
@@ -243,3 +302,17 @@ func lesserThan(first, second interface{}, t *Token) (bool, error) {
 		return false, InvalidOperationError(t, dt, getDataType(second))
 	}
 }
+
+// lookupMethod returns the method of a built in type bound to the receiver, suggesting the closest
+// method name of the table when there is none with the given name
+func lookupMethod[T any](table map[string]func(T) *NativeFunction, receiver T, name *Token) (interface{}, error) {
+	method, ok := table[name.lexeme]
+	if !ok {
+		names := make([]string, 0, len(table))
+		for name := range table {
+			names = append(names, name)
+		}
+		return nil, InvalidProperty(name, closestName(name.lexeme, names))
+	}
+	return method(receiver), nil
+}
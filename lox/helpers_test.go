@@ -0,0 +1,55 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"name", "name", 0},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1},
+		{"count", "conut", 1},
+		{"café", "cafe", 1},
+	}
+
+	for _, test := range tests {
+		if d := lox.EditDistance(test.a, test.b); d != test.distance {
+			t.Errorf("expected the distance between %q and %q to be %d, got %d", test.a, test.b, test.distance, d)
+		}
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		closest    string
+	}{
+		{"x", []string{"y"}, "y"},
+		{"counter", []string{"county"}, "county"},
+		{"counter", []string{"aaunter", "countr"}, "countr"},
+		// the name itself is not a suggestion
+		{"a", []string{"a"}, ""},
+		// candidates further than a third of the name are discarded
+		{"ab", []string{"xy"}, ""},
+		{"counter", []string{"cart"}, ""},
+		{"x", nil, ""},
+		// ties are broken alphabetically, whatever the order of the candidates
+		{"cat", []string{"hat", "cap", "bat"}, "bat"},
+		{"cat", []string{"bat", "hat", "cap"}, "bat"},
+	}
+
+	for _, test := range tests {
+		if closest := lox.ClosestName(test.name, test.candidates); closest != test.closest {
+			t.Errorf("expected %q to be the closest to %q among %v, got %q", test.closest, test.name, test.candidates, closest)
+		}
+	}
+}
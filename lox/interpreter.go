@@ -1,24 +1,162 @@
 package lox
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 )
 
+// Option configures an Interpreter
+type Option func(i *Interpreter)
+
+// WithOutput sets the writer where print statements write to. Defaults to the standard output.
+func WithOutput(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.output = w
+	}
+}
+
+// WithDebugger lets a debugger pause the program before executing its statements
+func WithDebugger(d *Debugger) Option {
+	return func(i *Interpreter) {
+		i.debugger = d
+		d.interpreter = i
+	}
+}
+
+// WithProfiler measures the time spent in every function of the program
+func WithProfiler(p *Profiler) Option {
+	return func(i *Interpreter) {
+		i.profiler = p
+		p.interpreter = i
+	}
+}
+
+// WithCoverage records the statements and branches of the program that run
+func WithCoverage(c *Coverage) Option {
+	return func(i *Interpreter) {
+		i.coverage = c
+	}
+}
+
+// WithStepLimit stops the program with a StepLimitExceeded error once it evaluates more than n
+// statements, expressions and loop iterations
+func WithStepLimit(n int) Option {
+	return func(i *Interpreter) {
+		i.stepLimit = n
+	}
+}
+
+// WithDeadline stops the program with a DeadlineExceeded error when it is still running at t
+func WithDeadline(t time.Time) Option {
+	return func(i *Interpreter) {
+		i.deadline = t
+	}
+}
+
+// WithContext stops the program with an ExecutionCanceled error once the context is done
+func WithContext(ctx context.Context) Option {
+	return func(i *Interpreter) {
+		i.ctx = ctx
+	}
+}
+
+// defaultDepthLimit of calls, deep enough for recursive programs and far from overflowing the stack
+// of the Go runtime, which crashes the process
+const defaultDepthLimit = 10000
+
+// WithDepthLimit stops the program with a StackOverflow error when a call is nested deeper than n
+// calls. Defaults to 10000. Unlike the other limits, 0 or less keeps the default instead of removing
+// the limit, since calls nested without a bound crash the process.
+func WithDepthLimit(n int) Option {
+	return func(i *Interpreter) {
+		if n > 0 {
+			i.depthLimit = n
+		}
+	}
+}
+
 // NewInterpreter constructor
-func NewInterpreter() *Interpreter {
-	e := NewEnvironment(nil)
-	e.define("clock", NewClockFunction())
-	return &Interpreter{environment: e, locals: map[Expression]int{}}
+func NewInterpreter(options ...Option) *Interpreter {
+	globals := NewEnvironment(nil)
+	globals.define("clock", NewClockFunction())
+	globals.define("env", NewEnvFunction())
+	globals.define("assert", NewAssertFunction())
+	globals.define("assertEqual", NewAssertEqualFunction())
+	globals.define("assertThrows", NewAssertThrowsFunction())
+	globals.define("str", NewStrFunction())
+	globals.define("num", NewNumFunction())
+	globals.define("format", NewFormatFunction())
+	globals.define("printf", NewPrintfFunction())
+	globals.define("math", NewMathNamespace())
+	globals.define("fs", NewFSNamespace())
+	globals.define("json", NewJSONNamespace())
+	globals.define("time", NewTimeNamespace())
+
+	i := &Interpreter{
+		globals:     globals,
+		environment: globals,
+		locals:      map[Expression]int{},
+		output:      os.Stdout,
+		depthLimit:  defaultDepthLimit,
+		clock:       systemClock{},
+		usage:       &counters{},
+	}
+
+	for _, option := range options {
+		option(i)
+	}
+
+	i.pushFrame(scriptFrame, nil, nil)
+
+	return i
 }
 
 // Interpreter of the lox language
 type Interpreter struct {
+	globals     *Environment
 	environment *Environment
 	locals      map[Expression]int
+	output      io.Writer
+	// frames is the call stack, the script frame is at the bottom
+	frames   []*Frame
+	debugger *Debugger
+	profiler *Profiler
+	coverage *Coverage
+	// circuit is the flag of the last break, continue or return statement executed. While it is
+	// set the statements of the enclosing blocks are skipped until the loop or function it belongs
+	// to resets it.
+	circuit *bool
+	// steps evaluated so far, checked against stepLimit when it is not zero
+	steps     int
+	stepLimit int
+	deadline  time.Time
+	ctx       context.Context
+	// depthLimit of nested calls, the script frame is not counted
+	depthLimit int
+	// usage of memory, checked against memoryLimit and objectLimit when they are not zero
+	usage       *counters
+	memoryLimit int
+	objectLimit int
+	// capabilities granted to the natives
+	capabilities []Capability
+	// root directory of the files scripts access, any when it is empty
+	root string
+	// clock of the natives that read the time
+	clock Clock
+	// files opened by the script and not closed yet
+	files map[*File]bool
 }
 
 // Interpret the given expression
 func (i *Interpreter) Interpret(s []Stmt) error {
+	if i.coverage != nil {
+		i.coverage.add(s)
+	}
+
 	for _, stmt := range s {
 		v, err := i.execute(stmt)
 		if err != nil {
@@ -26,7 +164,7 @@ func (i *Interpreter) Interpret(s []Stmt) error {
 		}
 		if v != nil {
 			if _, ok := v.(Callable); !ok {
-				fmt.Printf("%v\n", v)
+				fmt.Fprintf(i.output, "%v\n", v)
 			}
 		}
 	}
@@ -37,26 +175,20 @@ func (i *Interpreter) Resolve(e Expression, distance int) {
 	i.locals[e] = distance
 }
 
-func (i *Interpreter) lookUpVariable(lexeme string, e Expression) (interface{}, error) {
+// lookUpVariable named by the token of a variable or this expression, in the scope it resolved to
+func (i *Interpreter) lookUpVariable(name *Token, e Expression) (interface{}, error) {
 	var v interface{}
 	var found bool
 
 	distance, ok := i.locals[e]
 	if ok {
-		v, found = i.environment.getAt(lexeme, distance)
+		v, found = i.environment.getAt(name.lexeme, distance)
 	} else {
-		v, found = i.environment.get(lexeme)
+		v, found = i.environment.get(name.lexeme)
 	}
 
 	if !found {
-		var t *Token
-		if variable, ok := e.(*Variable); ok {
-			t = variable.token
-		} else {
-			this := e.(*This)
-			t = this.keyword
-		}
-		return nil, UndefinedVariable(lexeme, t)
+		return nil, UndefinedVariable(name.lexeme, name, closestName(name.lexeme, i.environment.names()))
 	}
 
 	return v, nil
@@ -64,6 +196,11 @@ func (i *Interpreter) lookUpVariable(lexeme string, e Expression) (interface{},
 }
 
 func (i *Interpreter) stringify(v interface{}) string {
+	return Stringify(v)
+}
+
+// Stringify a value the way print statements do
+func Stringify(v interface{}) string {
 	dt := getDataType(v)
 	if dt == object {
 		if v == nil {
@@ -79,11 +216,52 @@ func (i *Interpreter) stringify(v interface{}) string {
 	return fmt.Sprintf("%v", v)
 }
 
+// limit returns the error of the first execution limit the program exceeded, if any. It is
+// checked on every iteration of a loop and every call, the only ways a program can run forever.
+func (i *Interpreter) limit(t *Token) error {
+	if i.stepLimit > 0 && i.steps > i.stepLimit {
+		return StepLimitExceeded(t, i.stepLimit)
+	}
+
+	if !i.deadline.IsZero() && time.Now().After(i.deadline) {
+		return DeadlineExceeded(t)
+	}
+
+	if i.ctx != nil {
+		if err := i.ctx.Err(); err != nil {
+			return ExecutionCanceled(t, err)
+		}
+	}
+	return i.quota(t)
+}
+
 func (i *Interpreter) execute(s Stmt) (interface{}, error) {
+	i.steps++
+	f := i.frame()
+	f.environment = i.environment
+	if p, ok := statementPosition(s); ok {
+		if i.profiler != nil {
+			i.profiler.tick(i.frames)
+		}
+
+		newLine := p.Line != f.Position.Line
+		f.Position = p
+		if i.debugger != nil {
+			if err := i.debugger.before(newLine); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if i.coverage != nil {
+		i.coverage.statement(s)
+	}
+
 	return s.Accept(i)
 }
 
 func (i *Interpreter) evaluate(e Expression) (interface{}, error) {
+	i.steps++
 	return e.Accept(i)
 }
 
@@ -91,6 +269,26 @@ func (i *Interpreter) visitGrouping(e *Grouping) (interface{}, error) {
 	return i.evaluate(e.expression)
 }
 
+// visitInterpolation accounts for the string before it is built, as concatenate does, so
+// interpolating a string into itself in a loop can't exhaust the memory before the quota is checked
+func (i *Interpreter) visitInterpolation(e *Interpolation) (interface{}, error) {
+	parts := make([]string, len(e.parts))
+	length := 0
+	for n, part := range e.parts {
+		v, err := i.evaluate(part)
+		if err != nil {
+			return nil, err
+		}
+		parts[n] = Stringify(v)
+		length += len(parts[n])
+	}
+
+	if err := i.reserve(e.quote, length); err != nil {
+		return nil, err
+	}
+	return strings.Join(parts, ""), nil
+}
+
 func (i *Interpreter) visitLiteral(e *Literal) (interface{}, error) {
 	return e.value, nil
 }
@@ -109,7 +307,7 @@ func (i *Interpreter) visitUnary(e *Unary) (interface{}, error) {
 		}
 		return -v, nil
 	case BANG:
-		return isTruthy(right), nil
+		return !isTruthy(right), nil
 	default:
 		return nil, nil
 	}
@@ -165,6 +363,11 @@ func (i *Interpreter) visitBinary(e *Binary) (interface{}, error) {
 
 		return v1 / v2, nil
 	case PLUS:
+		l, lok := left.(string)
+		r, rok := right.(string)
+		if lok && rok {
+			return i.concatenate(l, r, e.operator)
+		}
 		return addValues(left, right, e.operator)
 	}
 
@@ -172,7 +375,7 @@ func (i *Interpreter) visitBinary(e *Binary) (interface{}, error) {
 }
 
 func (i *Interpreter) visitVariable(e *Variable) (interface{}, error) {
-	return i.lookUpVariable(e.token.lexeme, e)
+	return i.lookUpVariable(e.token, e)
 }
 
 // assignment → IDENTIFIER "=" assignment | equality ;
@@ -182,9 +385,15 @@ func (i *Interpreter) visitAssign(e *Assign) (interface{}, error) {
 		return nil, err
 	}
 
-	ok := i.environment.assign(e.name.lexeme, value)
+	var ok bool
+	if distance, resolved := i.locals[e]; resolved {
+		ok = i.environment.assignAt(e.name.lexeme, value, distance)
+	} else {
+		ok = i.environment.assign(e.name.lexeme, value)
+	}
+
 	if !ok {
-		return nil, UndefinedVariable(e.name.lexeme, e.name)
+		return nil, UndefinedVariable(e.name.lexeme, e.name, closestName(e.name.lexeme, i.environment.names()))
 	}
 
 	return nil, nil
@@ -196,14 +405,13 @@ func (i *Interpreter) visitLogical(e *Logical) (interface{}, error) {
 		return nil, err
 	}
 
-	if e.operator.Is(OR) {
-		if isTruthy(v) {
-			return v, nil
-		}
-	} else {
-		if !isTruthy(v) {
-			return v, nil
-		}
+	shortCircuit := isTruthy(v) == e.operator.Is(OR)
+	if i.coverage != nil {
+		i.coverage.branch(e, shortCircuit)
+	}
+
+	if shortCircuit {
+		return v, nil
 	}
 
 	return i.evaluate(e.right)
@@ -229,6 +437,10 @@ func (i *Interpreter) visitCall(e *Call) (interface{}, error) {
 		return nil, ExpressionIsNotCallable(e.paren)
 	}
 
+	if err := i.limit(e.paren); err != nil {
+		return nil, err
+	}
+
 	return c.Call(i, e.paren, arguments)
 }
 
@@ -238,8 +450,21 @@ func (i *Interpreter) visitGet(e *Get) (interface{}, error) {
 		return nil, err
 	}
 
-	if instance, ok := o.(*Instance); ok {
-		return instance.Get(e.name)
+	switch o := o.(type) {
+	case *Instance:
+		return o.Get(e.name)
+	case *Namespace:
+		return o.Get(e.name)
+	case string:
+		return lookupMethod(stringMethods, o, e.name)
+	case *List:
+		return lookupMethod(listMethods, o, e.name)
+	case *File:
+		return lookupMethod(fileMethods, o, e.name)
+	case *Date:
+		return lookupMethod(dateMethods, o, e.name)
+	case *Duration:
+		return lookupMethod(durationMethods, o, e.name)
 	}
 
 	return nil, NotAnObject(e.name)
@@ -266,7 +491,7 @@ func (i *Interpreter) visitSet(e *Set) (interface{}, error) {
 }
 
 func (i *Interpreter) visitThis(e *This) (interface{}, error) {
-	return i.lookUpVariable(e.keyword.lexeme, e)
+	return i.lookUpVariable(e.keyword, e)
 }
 
 func (i *Interpreter) visitPrintStmt(s *PrintStmt) (interface{}, error) {
@@ -275,7 +500,7 @@ func (i *Interpreter) visitPrintStmt(s *PrintStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	fmt.Println(i.stringify(value))
+	fmt.Fprintln(i.output, i.stringify(value))
 	return nil, nil
 }
 
@@ -308,29 +533,36 @@ func (i *Interpreter) visitBlockStmt(e *BlockStmt) (interface{}, error) {
 		i.environment = &prev
 	}()
 
-	i.environment = NewEnvironment(i.environment)
+	i.environment = i.newEnvironment(i.environment)
 	for _, statement := range e.statements {
 		v, err := i.execute(statement)
 		if err != nil {
 			return nil, err
 		}
 
-		if v != nil {
-			if _, ok := statement.(*CircuitBreakStmt); ok {
-				return v, nil
-			}
+		if i.breaking() {
+			return v, nil
 		}
 	}
 
 	return nil, nil
 }
 
+// breaking is true while a break, continue or return statement skips the rest of a block
+func (i *Interpreter) breaking() bool {
+	return i.circuit != nil && *i.circuit
+}
+
 func (i *Interpreter) visitIfStmt(e *IfStmt) (interface{}, error) {
 	v, err := i.evaluate(e.expression)
 	if err != nil {
 		return nil, err
 	}
 
+	if i.coverage != nil {
+		i.coverage.branch(e, isTruthy(v))
+	}
+
 	if isTruthy(v) {
 		return i.execute(e.thenBranch)
 	}
@@ -350,7 +582,7 @@ func (i *Interpreter) visitForStmt(e *ForStmt) (interface{}, error) {
 		i.environment = &prev
 	}()
 
-	i.environment = NewEnvironment(i.environment)
+	i.environment = i.newEnvironment(i.environment)
 	if e.initializer != nil {
 		_, err := i.execute(e.initializer)
 		if err != nil {
@@ -359,6 +591,12 @@ func (i *Interpreter) visitForStmt(e *ForStmt) (interface{}, error) {
 	}
 
 	for {
+		// Every iteration is a step, so loops without a condition nor a body still reach the limit
+		i.steps++
+		if err := i.limit(e.keyword); err != nil {
+			return nil, err
+		}
+
 		if e.condition != nil {
 			v, err := i.evaluate(e.condition)
 			if err != nil {
@@ -371,7 +609,7 @@ func (i *Interpreter) visitForStmt(e *ForStmt) (interface{}, error) {
 		}
 
 		for _, stmt := range e.body.statements {
-			_, err := i.execute(stmt)
+			v, err := i.execute(stmt)
 			if err != nil {
 				return nil, err
 			}
@@ -384,6 +622,11 @@ func (i *Interpreter) visitForStmt(e *ForStmt) (interface{}, error) {
 			if *e.br {
 				return nil, nil
 			}
+
+			// a return statement
+			if i.breaking() {
+				return v, nil
+			}
 		}
 
 		if e.increment != nil {
@@ -398,7 +641,7 @@ func (i *Interpreter) visitForStmt(e *ForStmt) (interface{}, error) {
 }
 
 func (i *Interpreter) visitFunctionStmt(e *FunctionStmt) (interface{}, error) {
-	f := NewFunction(e, i.environment)
+	f := i.newFunction(e, i.environment)
 	if e.name != nil {
 		i.environment.define(e.name.lexeme, f)
 	}
@@ -406,11 +649,20 @@ func (i *Interpreter) visitFunctionStmt(e *FunctionStmt) (interface{}, error) {
 }
 
 func (i *Interpreter) visitCircuitBreakStmt(e *CircuitBreakStmt) (interface{}, error) {
-	*e.value = true
+	var v interface{}
 	if e.statement != nil {
-		return i.execute(e.statement)
+		var err error
+		v, err = i.execute(e.statement)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return nil, nil
+
+	// The flag is set once the returned value is evaluated, since it is shared by the recursive
+	// calls made while evaluating it
+	*e.value = true
+	i.circuit = e.value
+	return v, nil
 }
 
 func (i *Interpreter) visitClassStmt(e *ClassStmt) (interface{}, error) {
@@ -432,7 +684,7 @@ func (i *Interpreter) visitClassStmt(e *ClassStmt) (interface{}, error) {
 
 	methods := map[string]*Function{}
 	for _, method := range e.methods {
-		methods[method.name.lexeme] = NewFunction(method, i.environment)
+		methods[method.name.lexeme] = i.newFunction(method, i.environment)
 	}
 
 	c := NewClass(e, super, methods)
@@ -18,11 +18,17 @@ type Iterator struct {
 	start   int
 	current int
 	line    int
-	column  int
+	// column is the amount of runes consumed in the current line
+	column int
+	// startLine and startColumn are the position of the first rune of the current lexeme
+	startLine   int
+	startColumn int
 }
 
 func (i *Iterator) startLexeme() {
 	i.start = i.current
+	i.startLine = i.line
+	i.startColumn = i.column + 1
 }
 
 func (i *Iterator) currentLexeme() string {
@@ -44,9 +50,10 @@ func (i *Iterator) advance() rune {
 	if r == linebreak {
 		i.column = 0
 		i.line++
+	} else {
+		i.column++
 	}
 
-	i.column++
 	return r
 }
 
@@ -58,7 +65,7 @@ func (i *Iterator) peek() rune {
 }
 
 func (i *Iterator) next() rune {
-	if i.isAtEnd() {
+	if i.current+1 >= len(i.source) {
 		return utf8.RuneError
 	}
 	return i.source[i.current+1]
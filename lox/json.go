@@ -0,0 +1,192 @@
+package lox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// objectClass of the instances json.parse builds out of JSON objects
+var objectClass = NewClass(&ClassStmt{name: &Token{lexeme: "Object"}}, nil, map[string]*Function{})
+
+// NewJSONNamespace constructor of the json namespace. JSON objects are read into instances whose
+// properties are their keys, arrays into lists, and null into nil.
+func NewJSONNamespace() *Namespace {
+	return NewNamespace("json", map[string]interface{}{
+		"parse": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			text, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			var v interface{}
+			if err := json.Unmarshal([]byte(text), &v); err != nil {
+				return nil, InvalidJSON(paren, err.Error())
+			}
+			return i.fromJSON(v), nil
+		}),
+		"stringify": NewNativeFunction(1, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// stringify a value in a single line, or indented by a number of spaces or a string
+			// given as second argument
+			if len(arguments) > 2 {
+				return nil, WrongNumberOfArguments(paren, len(arguments), 2)
+			}
+
+			indent := ""
+			if len(arguments) == 2 {
+				switch v := arguments[1].(type) {
+				case string:
+					indent = v
+				case float64:
+					if v < 0 || v > 10 || v != math.Trunc(v) {
+						return nil, InvalidJSON(paren, fmt.Sprintf("indentation of %v spaces, expecting up to 10", v))
+					}
+					indent = strings.Repeat(" ", int(v))
+				default:
+					return nil, InvalidDataTypeError(paren, getDataType(v), number)
+				}
+			}
+
+			c := &jsonConverter{i: i, paren: paren, indent: len(indent), visiting: map[interface{}]bool{}}
+			v, err := c.convert(arguments[0], 0)
+			if err != nil {
+				return nil, err
+			}
+
+			var b bytes.Buffer
+			encoder := json.NewEncoder(&b)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", indent)
+			if err := encoder.Encode(v); err != nil {
+				return nil, InvalidJSON(paren, err.Error())
+			}
+
+			if err := i.reserve(paren, b.Len()-1); err != nil {
+				return nil, err
+			}
+			return strings.TrimSuffix(b.String(), "\n"), nil
+		}),
+	})
+}
+
+// fromJSON converts a decoded JSON value to a Lox one
+func (i *Interpreter) fromJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return i.newString(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for n, value := range v {
+			values[n] = i.fromJSON(value)
+		}
+		return i.newList(values)
+	case map[string]interface{}:
+		i.allocate(instanceSize)
+		instance := &Instance{
+			class:      objectClass,
+			properties: map[string]interface{}{},
+			methods:    map[string]*Function{},
+		}
+		for key, value := range v {
+			i.allocate(valueSize)
+			instance.properties[key] = i.fromJSON(value)
+		}
+		return instance
+	}
+	return v
+}
+
+// jsonConverter converts a Lox value to one encoding/json writes. The lists and instances being
+// converted are kept in visiting, so values that contain themselves fail instead of recursing
+// forever. Values that hold the same list many times are written whole every time, so the length
+// of the output is estimated while converting and checked against the memory limit before
+// encoding/json builds it.
+type jsonConverter struct {
+	i        *Interpreter
+	paren    *Token
+	indent   int
+	visiting map[interface{}]bool
+	size     int
+}
+
+// grow the estimated length of the output
+func (c *jsonConverter) grow(n int) error {
+	c.size += n
+	if !c.i.fits(c.size) {
+		return MemoryLimitExceeded(c.paren, "bytes", c.i.memoryLimit)
+	}
+	return nil
+}
+
+// element accounts for the separator and the indentation of an element of a list or an object
+func (c *jsonConverter) element(depth int) error {
+	if c.indent == 0 {
+		return c.grow(1)
+	}
+	return c.grow(2 + (depth+1)*c.indent)
+}
+
+func (c *jsonConverter) convert(v interface{}, depth int) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, bool:
+		return v, c.grow(5)
+	case string:
+		return v, c.grow(len(v) + 2)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, InvalidJSON(c.paren, fmt.Sprintf("%v can't be written as JSON", v))
+		}
+		return v, c.grow(len(Stringify(v)))
+	case *List:
+		if c.visiting[v] {
+			return nil, InvalidJSON(c.paren, "the list contains itself")
+		}
+		c.visiting[v] = true
+		defer delete(c.visiting, v)
+
+		if err := c.grow(2); err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(v.values))
+		for n, value := range v.values {
+			if err := c.element(depth); err != nil {
+				return nil, err
+			}
+			converted, err := c.convert(value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values[n] = converted
+		}
+		return values, nil
+	case *Instance:
+		if c.visiting[v] {
+			return nil, InvalidJSON(c.paren, fmt.Sprintf("the %s contains itself", v))
+		}
+		c.visiting[v] = true
+		defer delete(c.visiting, v)
+
+		if err := c.grow(2); err != nil {
+			return nil, err
+		}
+		// encoding/json writes the keys sorted, so the output does not depend on the order of the map
+		properties := make(map[string]interface{}, len(v.properties))
+		for key, value := range v.properties {
+			if err := c.element(depth); err != nil {
+				return nil, err
+			}
+			if err := c.grow(len(key) + 4); err != nil {
+				return nil, err
+			}
+			converted, err := c.convert(value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			properties[key] = converted
+		}
+		return properties, nil
+	}
+	return nil, InvalidJSON(c.paren, fmt.Sprintf("%s can't be written as JSON", getDataType(v)))
+}
@@ -0,0 +1,45 @@
+package lox_test
+
+import (
+	"context"
+	"golox/lox"
+	"testing"
+	"time"
+)
+
+func TestStepLimit(t *testing.T) {
+	// Setting the loop up takes 5 steps and every iteration 8 more, one for the iteration itself. The
+	// limit is checked when each iteration starts, so the last check sees 30 steps
+	source := `for var i = 0; i < 3; i = i + 1 {
+}
+`
+	if _, err := interpret(t, source, lox.WithStepLimit(30)); err != nil {
+		t.Fatalf("expected the program to run within 30 steps, got %v", err)
+	}
+
+	_, err := interpret(t, source, lox.WithStepLimit(29))
+	if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.StepLimitExceededCode {
+		t.Fatalf("expected %s, got %v", lox.StepLimitExceededCode, err)
+	}
+}
+
+func TestStepLimitEmptyLoop(t *testing.T) {
+	_, err := interpret(t, `for {
+}
+`, lox.WithStepLimit(1000))
+	if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.StepLimitExceededCode {
+		t.Fatalf("expected %s, got %v", lox.StepLimitExceededCode, err)
+	}
+}
+
+func TestContextCanceledWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := interpret(t, `for {
+}
+`, lox.WithContext(ctx))
+	if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.ExecutionCanceledCode {
+		t.Fatalf("expected %s, got %v", lox.ExecutionCanceledCode, err)
+	}
+}
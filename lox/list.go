@@ -0,0 +1,109 @@
+package lox
+
+import "strings"
+
+// NewList constructor
+func NewList(values []interface{}) *List {
+	return &List{values: values}
+}
+
+// List of values, as the parts of a split string. Lists are objects, they are modified in place
+// and shared by reference.
+type List struct {
+	values []interface{}
+}
+
+func (l *List) String() string {
+	return l.format(map[*List]bool{})
+}
+
+// format the list with its values quoted. The lists being formatted are kept in visiting, so a list
+// that contains itself is shown as [...] instead of recursing forever.
+func (l *List) format(visiting map[*List]bool) string {
+	if visiting[l] {
+		return "[...]"
+	}
+	visiting[l] = true
+	defer delete(visiting, l)
+
+	parts := make([]string, len(l.values))
+	for n, v := range l.values {
+		if inner, ok := v.(*List); ok {
+			parts[n] = inner.format(visiting)
+		} else {
+			parts[n] = quote(v)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// listMethods are called on lists, as l.len()
+var listMethods = map[string]func(l *List) *NativeFunction{
+	"len": func(l *List) *NativeFunction {
+		return NewNativeFunction(0, false, func(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			return float64(len(l.values)), nil
+		})
+	},
+	"get": func(l *List) *NativeFunction {
+		return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			n, err := index(paren, arguments[0], len(l.values))
+			if err != nil {
+				return nil, err
+			}
+			return l.values[n], nil
+		})
+	},
+	"set": func(l *List) *NativeFunction {
+		return NewNativeFunction(2, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			n, err := index(paren, arguments[0], len(l.values))
+			if err != nil {
+				return nil, err
+			}
+			l.values[n] = arguments[1]
+			return nil, nil
+		})
+	},
+	"push": func(l *List) *NativeFunction {
+		return NewNativeFunction(1, false, func(i *Interpreter, _ *Token, arguments []interface{}) (interface{}, error) {
+			i.allocate(valueSize)
+			l.values = append(l.values, arguments[0])
+			return nil, nil
+		})
+	},
+	"pop": func(l *List) *NativeFunction {
+		// pop removes the last value and returns it
+		return NewNativeFunction(0, false, func(_ *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+			if len(l.values) == 0 {
+				return nil, InvalidIndex(paren, -1, 0)
+			}
+
+			v := l.values[len(l.values)-1]
+			l.values = l.values[:len(l.values)-1]
+			return v, nil
+		})
+	},
+	"join": func(l *List) *NativeFunction {
+		// join the values, as print shows them, with a separator between them
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			separator, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			parts := make([]string, len(l.values))
+			length := 0
+			for n, v := range l.values {
+				parts[n] = Stringify(v)
+				length += len(parts[n])
+			}
+
+			if len(parts) > 1 {
+				length += (len(parts) - 1) * len(separator)
+			}
+			if err := i.reserve(paren, length); err != nil {
+				return nil, err
+			}
+			return strings.Join(parts, separator), nil
+		})
+	},
+}
@@ -0,0 +1,45 @@
+package lox_test
+
+import (
+	"bytes"
+	"golox/lox"
+	"testing"
+)
+
+func parse(t *testing.T, source string) []lox.Stmt {
+	t.Helper()
+	tokens, errs := lox.NewScanner(source).ScanTokens()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+
+	stmts, errs := lox.NewParser(tokens).Parse()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+
+	return stmts
+}
+
+// resolve returns an interpreter with the given options ready to run the statements
+func resolve(t *testing.T, stmts []lox.Stmt, options ...lox.Option) *lox.Interpreter {
+	t.Helper()
+	i := lox.NewInterpreter(options...)
+	if _, err := lox.NewResolver(i).Resolve(stmts); err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+// interpret runs a program, it returns what the program printed and the error that stopped it
+func interpret(t *testing.T, source string, options ...lox.Option) (string, error) {
+	t.Helper()
+	stmts := parse(t, source)
+
+	var out bytes.Buffer
+	i := resolve(t, stmts, append([]lox.Option{lox.WithOutput(&out)}, options...)...)
+	defer i.Close()
+
+	err := i.Interpret(stmts)
+	return out.String(), err
+}
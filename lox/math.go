@@ -0,0 +1,157 @@
+package lox
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// NewMathNamespace constructor of the math namespace, its functions raise an InvalidDataType error
+// when they are given anything but numbers
+func NewMathNamespace() *Namespace {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return NewNamespace("math", map[string]interface{}{
+		"pi":  math.Pi,
+		"e":   math.E,
+		"inf": math.Inf(1),
+		"nan": math.NaN(),
+
+		"sqrt":  numeric(math.Sqrt),
+		"cbrt":  numeric(math.Cbrt),
+		"abs":   numeric(math.Abs),
+		"floor": numeric(math.Floor),
+		"ceil":  numeric(math.Ceil),
+		"round": numeric(math.Round),
+		"trunc": numeric(math.Trunc),
+		"sin":   numeric(math.Sin),
+		"cos":   numeric(math.Cos),
+		"tan":   numeric(math.Tan),
+		"asin":  numeric(math.Asin),
+		"acos":  numeric(math.Acos),
+		"atan":  numeric(math.Atan),
+		"exp":   numeric(math.Exp),
+		"log":   numeric(math.Log),
+		"log2":  numeric(math.Log2),
+		"log10": numeric(math.Log10),
+		"sign": numeric(func(x float64) float64 {
+			switch {
+			case x > 0:
+				return 1
+			case x < 0:
+				return -1
+			}
+			return x
+		}),
+
+		"pow":   numeric2(math.Pow),
+		"atan2": numeric2(math.Atan2),
+		"hypot": numeric2(math.Hypot),
+
+		"min": NewNativeFunction(1, true, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			return fold(paren, arguments, math.Min)
+		}),
+		"max": NewNativeFunction(1, true, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			return fold(paren, arguments, math.Max)
+		}),
+
+		"isNaN": predicate(math.IsNaN),
+		"isInf": predicate(func(x float64) bool {
+			return math.IsInf(x, 0)
+		}),
+		"isInteger": predicate(func(x float64) bool {
+			return x == math.Trunc(x) && !math.IsInf(x, 0)
+		}),
+
+		// div and mod are the quotient truncated toward zero and the remainder of an integer
+		// division, the remainder has the sign of the dividend
+		"div": NewNativeFunction(2, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			x, err := numbers(paren, arguments)
+			if err != nil {
+				return nil, err
+			}
+			if x[1] == 0 {
+				return nil, DivisionByZeroError(paren)
+			}
+			return math.Trunc(x[0] / x[1]), nil
+		}),
+		"mod": NewNativeFunction(2, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			x, err := numbers(paren, arguments)
+			if err != nil {
+				return nil, err
+			}
+			if x[1] == 0 {
+				return nil, DivisionByZeroError(paren)
+			}
+			return math.Mod(x[0], x[1]), nil
+		}),
+
+		// random returns a number in [0, 1)
+		"random": NewNativeFunction(0, false, func(i *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+			if err := i.require(paren, RandomCapability); err != nil {
+				return nil, err
+			}
+			return random.Float64(), nil
+		}),
+	})
+}
+
+// numbers returns the arguments of a call as numbers
+func numbers(paren *Token, arguments []interface{}) ([]float64, error) {
+	x := make([]float64, len(arguments))
+	for n, argument := range arguments {
+		v, ok := argument.(float64)
+		if !ok {
+			return nil, InvalidDataTypeError(paren, getDataType(argument), number)
+		}
+		x[n] = v
+	}
+	return x, nil
+}
+
+// numeric native of a function of a number
+func numeric(f func(float64) float64) *NativeFunction {
+	return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		x, err := numbers(paren, arguments)
+		if err != nil {
+			return nil, err
+		}
+		return f(x[0]), nil
+	})
+}
+
+// numeric2 native of a function of two numbers
+func numeric2(f func(float64, float64) float64) *NativeFunction {
+	return NewNativeFunction(2, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		x, err := numbers(paren, arguments)
+		if err != nil {
+			return nil, err
+		}
+		return f(x[0], x[1]), nil
+	})
+}
+
+// predicate native of a number
+func predicate(f func(float64) bool) *NativeFunction {
+	return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		x, err := numbers(paren, arguments)
+		if err != nil {
+			return nil, err
+		}
+		return f(x[0]), nil
+	})
+}
+
+// fold the numbers given as arguments into one
+func fold(paren *Token, arguments []interface{}, f func(float64, float64) float64) (interface{}, error) {
+	x, err := numbers(paren, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	v := x[0]
+	for _, y := range x[1:] {
+		v = f(v, y)
+	}
+	return v, nil
+}
@@ -0,0 +1,123 @@
+package lox
+
+import "sync/atomic"
+
+// Estimated sizes in bytes of the values a program allocates. They don't need to be exact, only
+// to grow with what the program allocates so quotas can be enforced.
+const (
+	stringSize      = 16
+	instanceSize    = 64
+	functionSize    = 48
+	environmentSize = 48
+	listSize        = 24
+	valueSize       = 16
+)
+
+// Usage of memory of a program, the total of what it allocated since it started. Memory is never
+// given back, so quotas bound how much a program allocates rather than how much it holds at once.
+type Usage struct {
+	// Bytes allocated, estimated
+	Bytes int
+	// Objects allocated: strings built by concatenation and natives, lists, instances, functions
+	// and environments
+	Objects int
+}
+
+// WithMemoryLimit stops the program with a MemoryLimitExceeded error once it allocates more than
+// the given number of bytes
+func WithMemoryLimit(bytes int) Option {
+	return func(i *Interpreter) {
+		i.memoryLimit = bytes
+	}
+}
+
+// WithObjectLimit stops the program with a MemoryLimitExceeded error once it allocates more than
+// n objects
+func WithObjectLimit(n int) Option {
+	return func(i *Interpreter) {
+		i.objectLimit = n
+	}
+}
+
+// counters of what a program allocated. They are updated atomically so monitors can read them from
+// other goroutines while the program runs, and kept apart from the interpreter so they are 64-bit
+// aligned on every platform.
+type counters struct {
+	bytes   int64
+	objects int64
+}
+
+// Usage returns the memory allocated so far by the program. It is safe to call from any goroutine,
+// also while the program runs.
+func (i *Interpreter) Usage() Usage {
+	return Usage{
+		Bytes:   int(atomic.LoadInt64(&i.usage.bytes)),
+		Objects: int(atomic.LoadInt64(&i.usage.objects)),
+	}
+}
+
+// allocate accounts for an object of the given size. Exceeding the quotas is only checked by
+// quota, allocations that can't grow without bounds are checked at the next loop iteration or call.
+func (i *Interpreter) allocate(bytes int) {
+	atomic.AddInt64(&i.usage.bytes, int64(bytes))
+	atomic.AddInt64(&i.usage.objects, 1)
+}
+
+// quota returns an error when the program allocated more than its limits
+func (i *Interpreter) quota(t *Token) error {
+	usage := i.Usage()
+	if i.memoryLimit > 0 && usage.Bytes > i.memoryLimit {
+		return MemoryLimitExceeded(t, "bytes", i.memoryLimit)
+	}
+
+	if i.objectLimit > 0 && usage.Objects > i.objectLimit {
+		return MemoryLimitExceeded(t, "objects", i.objectLimit)
+	}
+	return nil
+}
+
+// newEnvironment allocates an environment enclosed by the given one
+func (i *Interpreter) newEnvironment(enclosing *Environment) *Environment {
+	i.allocate(environmentSize)
+	return NewEnvironment(enclosing)
+}
+
+// newFunction allocates a function whose closure is the given environment
+func (i *Interpreter) newFunction(statement *FunctionStmt, closure *Environment) *Function {
+	i.allocate(functionSize)
+	return NewFunction(statement, closure)
+}
+
+// newString accounts for a string built by a native
+func (i *Interpreter) newString(s string) string {
+	i.allocate(stringSize + len(s))
+	return s
+}
+
+// newList allocates a list of the given values
+func (i *Interpreter) newList(values []interface{}) *List {
+	i.allocate(listSize + len(values)*valueSize)
+	return NewList(values)
+}
+
+// reserve accounts for a string of the given length before it is built, so a program can't exhaust
+// the memory of the process building it before the quota is checked
+func (i *Interpreter) reserve(t *Token, length int) error {
+	i.allocate(stringSize + length)
+	return i.quota(t)
+}
+
+// fits tells whether a string of the given length can still be allocated within the memory limit,
+// for the strings whose length is only known once they are built
+func (i *Interpreter) fits(length int) bool {
+	return i.memoryLimit <= 0 || i.Usage().Bytes+stringSize+length <= i.memoryLimit
+}
+
+// concatenate two strings, the result is accounted before it is built so a program doubling a
+// string can't exhaust the memory of the process before the quota is checked
+func (i *Interpreter) concatenate(left, right string, t *Token) (string, error) {
+	if err := i.reserve(t, len(left)+len(right)); err != nil {
+		return "", err
+	}
+	return left + right, nil
+}
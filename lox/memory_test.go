@@ -0,0 +1,132 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"io/ioutil"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	stmts := parse(t, `class Point {
+    init(x) {
+        this.x = x;
+    }
+}
+
+var points = 0;
+for var i = 0; i < 10; i = i + 1 {
+    var p = Point(i);
+    points = points + p.x;
+}
+print points;
+`)
+
+	i := resolve(t, stmts, lox.WithOutput(ioutil.Discard), lox.WithObjectLimit(1000))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := i.Usage()
+	if usage.Objects < 10 || usage.Bytes <= 0 {
+		t.Fatalf("expected the instances to be accounted, got %+v", usage)
+	}
+
+	i = resolve(t, stmts, lox.WithOutput(ioutil.Discard), lox.WithObjectLimit(usage.Objects-1))
+	if err, ok := i.Interpret(stmts).(*lox.RuntimeError); !ok || err.Code() != lox.MemoryLimitExceededCode {
+		t.Fatalf("expected %s, got %v", lox.MemoryLimitExceededCode, err)
+	}
+}
+
+func TestUsageWhileRunning(t *testing.T) {
+	stmts := parse(t, `class Point {}
+for var i = 0; i < 20000; i = i + 1 {
+    Point();
+}
+`)
+
+	i := resolve(t, stmts, lox.WithOutput(ioutil.Discard))
+
+	// A monitor reads the usage while the program runs, go test -race checks it is safe
+	done := make(chan error)
+	go func() {
+		done <- i.Interpret(stmts)
+	}()
+
+	last := 0
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			if objects := i.Usage().Objects; objects < 20000 {
+				t.Fatalf("expected the instances to be accounted, got %d objects", objects)
+			}
+			return
+		default:
+			objects := i.Usage().Objects
+			if objects < last {
+				t.Fatalf("usage went back from %d to %d objects", last, objects)
+			}
+			last = objects
+		}
+	}
+}
+
+func TestStringsWithinMemoryLimit(t *testing.T) {
+	// Each program grows a string faster than the quota is checked by loops and calls, the natives
+	// and interpolations building it must check the result before they allocate it
+	tests := map[string]string{
+		"replace":       `s = s.replace("a", s);`,
+		"join":          `s = s.split("").join(s);`,
+		"format":        `s = format("%s%s%s", s, s, s);`,
+		"interpolation": `s = "${s}${s}${s}";`,
+	}
+
+	for name, statement := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interpret(t, `var s = "a";
+for var i = 0; i < 12; i = i + 1 {
+    s = s + s;
+}
+for {
+    `+statement+`
+}
+`, lox.WithMemoryLimit(64<<20))
+			if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.MemoryLimitExceededCode {
+				t.Fatalf("expected %s, got %v", lox.MemoryLimitExceededCode, err)
+			}
+		})
+	}
+}
+
+func TestJSONWithinMemoryLimit(t *testing.T) {
+	// The output of json.stringify is much longer than the values it writes when they hold the
+	// same list many times or when it indents deeply nested ones
+	tests := map[string]string{
+		"shared": `var l = "a".split(" ");
+for var i = 0; i < 40; i = i + 1 {
+    var m = "a a".split(" ");
+    m.set(0, l);
+    m.set(1, l);
+    l = m;
+}
+json.stringify(l);`,
+		"indented": `var l = "a".split(" ");
+for var i = 0; i < 3000; i = i + 1 {
+    var m = "a".split(" ");
+    m.set(0, l);
+    l = m;
+}
+json.stringify(l, 10);`,
+	}
+
+	for name, source := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interpret(t, source, lox.WithMemoryLimit(1<<20))
+			if err, ok := err.(*lox.RuntimeError); !ok || err.Code() != lox.MemoryLimitExceededCode {
+				t.Fatalf("expected %s, got %v", lox.MemoryLimitExceededCode, err)
+			}
+		})
+	}
+}
@@ -1,19 +1,165 @@
 package lox
 
-import "time"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NewNativeFunction constructor, variadic functions take any number of arguments but at least
+// arity of them
+func NewNativeFunction(arity int, variadic bool, call func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error)) *NativeFunction {
+	return &NativeFunction{
+		arity:    arity,
+		variadic: variadic,
+		call:     call,
+	}
+}
+
+// NativeFunction implemented by a Go function, which is called once the number of arguments is
+// checked
+type NativeFunction struct {
+	arity    int
+	variadic bool
+	call     func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error)
+}
+
+func (n *NativeFunction) Call(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+	if len(arguments) != n.arity && (!n.variadic || len(arguments) < n.arity) {
+		return nil, WrongNumberOfArguments(paren, len(arguments), n.arity)
+	}
+	return n.call(i, paren, arguments)
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
 
 // NewClockFunction constructor
-func NewClockFunction() *Clock {
-	return &Clock{}
+func NewClockFunction() *NativeFunction {
+	// clock returns the seconds elapsed since the Unix epoch, with a fraction, as in the book. It
+	// stays in seconds for the programs written for it, while time.now and dates count milliseconds.
+	return NewNativeFunction(0, false, func(i *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+		if err := i.require(paren, ClockCapability); err != nil {
+			return nil, err
+		}
+		return float64(i.clock.Now().UnixNano()) / float64(time.Second), nil
+	})
+}
+
+// NewEnvFunction constructor
+func NewEnvFunction() *NativeFunction {
+	// env returns the value of an environment variable, nil when it is not set
+	return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		if err := i.require(paren, EnvCapability); err != nil {
+			return nil, err
+		}
+
+		name, err := stringArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, nil
+		}
+		return i.newString(value), nil
+	})
+}
+
+// NewAssertFunction constructor
+func NewAssertFunction() *Assert {
+	return &Assert{}
+}
+
+// Assert native function, it fails when its condition is falsey. The message is optional.
+type Assert struct{}
+
+func (a *Assert) Call(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+	if len(arguments) != 1 && len(arguments) != 2 {
+		return nil, WrongNumberOfArguments(paren, len(arguments), 1)
+	}
+
+	if isTruthy(arguments[0]) {
+		return nil, nil
+	}
+
+	if len(arguments) == 2 {
+		return nil, AssertionFailed(paren, "assertion failed: "+Stringify(arguments[1]))
+	}
+	return nil, AssertionFailed(paren, "assertion failed")
 }
 
-// Clock native function
-type Clock struct{}
+func (a *Assert) String() string {
+	return "<native fn>"
+}
+
+// NewAssertEqualFunction constructor
+func NewAssertEqualFunction() *AssertEqual {
+	return &AssertEqual{}
+}
+
+// AssertEqual native function, it fails when the expected and the actual values are different.
+// Objects are equal only to themselves.
+type AssertEqual struct{}
+
+func (a *AssertEqual) Call(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+	if len(arguments) != 2 {
+		return nil, WrongNumberOfArguments(paren, len(arguments), 2)
+	}
+
+	expected, actual := arguments[0], arguments[1]
+	if expected == actual {
+		return nil, nil
+	}
+	return nil, AssertionFailed(paren, fmt.Sprintf("expected %s, got %s", quote(expected), quote(actual)))
+}
+
+func (a *AssertEqual) String() string {
+	return "<native fn>"
+}
+
+// quote strings so that they can be told apart from other values in failure messages
+func quote(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return Stringify(v)
+}
+
+// NewAssertThrowsFunction constructor
+func NewAssertThrowsFunction() *AssertThrows {
+	return &AssertThrows{}
+}
+
+// AssertThrows native function, it calls a function without arguments and fails unless it raises
+// a runtime error. It returns the description of the error.
+type AssertThrows struct{}
+
+func (a *AssertThrows) Call(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+	if len(arguments) != 1 {
+		return nil, WrongNumberOfArguments(paren, len(arguments), 1)
+	}
+
+	c, ok := arguments[0].(Callable)
+	if !ok {
+		return nil, InvalidDataTypeError(paren, getDataType(arguments[0]), function)
+	}
+
+	_, err := c.Call(i, paren, nil)
+	if err == nil {
+		return nil, AssertionFailed(paren, "expected an error to be raised")
+	}
 
-func (c *Clock) Call(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
-	return time.Now().UnixNano() / int64(time.Microsecond), nil
+	e, ok := err.(*RuntimeError)
+	if !ok {
+		return nil, err
+	}
+	return e.err.description, nil
 }
 
-func (c *Clock) String() string {
+func (a *AssertThrows) String() string {
 	return "<native fn>"
 }
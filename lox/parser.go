@@ -1,5 +1,7 @@
 package lox
 
+import "strings"
+
 // NewParser constructor
 func NewParser(tokens []*Token) *Parser {
 	return &Parser{tokens: tokens}
@@ -48,7 +50,11 @@ func (p *Parser) Parse() ([]Stmt, []error) {
 	for !p.isAtEnd() {
 		statement, err := p.declaration(nil, nil, nil)
 		if err != nil {
-			errs = append(errs, err)
+			// Errors next to ERROR tokens are a consequence of a lexical error that was already
+			// reported by the scanner
+			if !p.current().Is(ERROR) && !(p.previous() != nil && p.previous().Is(ERROR)) {
+				errs = append(errs, err)
+			}
 			p.synchronize()
 			continue
 		}
@@ -59,7 +65,7 @@ func (p *Parser) Parse() ([]Stmt, []error) {
 		return nil, errs
 	}
 
-	return []Stmt{NewBlockStmt(s)}, nil
+	return []Stmt{NewBlockStmt(s, nil)}, nil
 }
 
 func (p *Parser) synchronize() {
@@ -117,6 +123,8 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else if !p.match(SEMICOLON) {
+		return nil, ExpectedSemicolonError(p.current())
 	}
 
 	return NewVarStmt(name, initializer), nil
@@ -190,6 +198,10 @@ func (p *Parser) classDeclaration() (Stmt, error) {
 
 	var methods []*FunctionStmt
 	for !p.current().Is(RIGHT_BRACE) && !p.isAtEnd() {
+		if !p.current().Is(IDENTIFIER) {
+			return nil, ExpectedIdentifier(p.current())
+		}
+
 		f, err := p.funDeclaration()
 		if err != nil {
 			return nil, err
@@ -234,6 +246,7 @@ func (p *Parser) statement(br, cont, rt *bool) (Stmt, error) {
 func (p *Parser) forStatement(rt *bool) (*ForStmt, error) {
 	var err error
 
+	keyword := p.previous()
 	cont := false
 	br := false
 
@@ -242,7 +255,7 @@ func (p *Parser) forStatement(rt *bool) (*ForStmt, error) {
 		if err != nil {
 			return nil, err
 		}
-		return NewForStmt(nil, nil, nil, body, &br, &cont), nil
+		return NewForStmt(keyword, nil, nil, nil, body, &br, &cont), nil
 	}
 
 	var initializer Stmt
@@ -258,7 +271,7 @@ func (p *Parser) forStatement(rt *bool) (*ForStmt, error) {
 		if err != nil {
 			return nil, err
 		}
-		return NewForStmt(initializer, nil, nil, body, &br, &cont), nil
+		return NewForStmt(keyword, initializer, nil, nil, body, &br, &cont), nil
 	}
 
 	var conditional Expression
@@ -291,10 +304,11 @@ func (p *Parser) forStatement(rt *bool) (*ForStmt, error) {
 		return nil, err
 	}
 
-	return NewForStmt(initializer, conditional, increment, body, &br, &cont), nil
+	return NewForStmt(keyword, initializer, conditional, increment, body, &br, &cont), nil
 }
 
 func (p *Parser) ifStatement(br, cont, rt *bool) (*IfStmt, error) {
+	keyword := p.previous()
 	expression, err := p.expression()
 	if err != nil {
 		return nil, err
@@ -321,20 +335,21 @@ func (p *Parser) ifStatement(br, cont, rt *bool) (*IfStmt, error) {
 		}
 	}
 
-	return NewIfStmt(expression, thenBranch, elseBranch), nil
+	return NewIfStmt(keyword, expression, thenBranch, elseBranch), nil
 }
 
 func (p *Parser) printStatement() (*PrintStmt, error) {
+	keyword := p.previous()
 	e, err := p.expression()
 	if err != nil {
 		return nil, err
 	}
 
-	if !p.advance().Is(SEMICOLON) {
+	if !p.match(SEMICOLON) {
 		return nil, ExpectedSemicolonError(p.current())
 	}
 
-	return NewPrintStmt(e), nil
+	return NewPrintStmt(keyword, e), nil
 }
 
 func (p *Parser) expressionStatement() (*ExpressionStmt, error) {
@@ -361,7 +376,7 @@ func (p *Parser) blockStatement(br, cont, rt *bool) (*BlockStmt, error) {
 				return nil, BreakStatementOutsideLoop(p.previous())
 			}
 
-			statement = NewCircuitBreakStmt(br, nil)
+			statement = NewCircuitBreakStmt(p.previous(), br, nil)
 			if !p.match(SEMICOLON) {
 				return nil, ExpectedSemicolonError(p.current())
 			}
@@ -370,24 +385,35 @@ func (p *Parser) blockStatement(br, cont, rt *bool) (*BlockStmt, error) {
 				return nil, ContinueStatementOutsideLoop(p.previous())
 			}
 
-			statement = NewCircuitBreakStmt(cont, nil)
+			statement = NewCircuitBreakStmt(p.previous(), cont, nil)
 			if !p.match(SEMICOLON) {
 				return nil, ExpectedSemicolonError(p.current())
 			}
 		} else if p.match(RETURN) {
+			keyword := p.previous()
 			if rt == nil {
 				return nil, ReturnStatementOutsideFunction(p.current())
 			}
 
 			var e Stmt
-			if !p.match(SEMICOLON) {
+			if p.match(FUN) {
+				// a lambda is followed by a semicolon like any other returned value
+				e, err = p.funDeclaration()
+				if err != nil {
+					return nil, err
+				}
+
+				if !p.match(SEMICOLON) {
+					return nil, ExpectedSemicolonError(p.current())
+				}
+			} else if !p.match(SEMICOLON) {
 				e, err = p.declaration(nil, nil, nil)
 				if err != nil {
 					return nil, err
 				}
 			}
 
-			statement = NewCircuitBreakStmt(rt, e)
+			statement = NewCircuitBreakStmt(keyword, rt, e)
 		} else {
 			statement, err = p.declaration(br, cont, rt)
 			if err != nil {
@@ -402,7 +428,7 @@ func (p *Parser) blockStatement(br, cont, rt *bool) (*BlockStmt, error) {
 		return nil, ExpectedEndingBrace(p.current())
 	}
 
-	return NewBlockStmt(s), nil
+	return NewBlockStmt(s, p.previous()), nil
 }
 
 // expression → assignment
@@ -421,7 +447,7 @@ func (p *Parser) assignment() (Expression, error) {
 		return e, nil
 	}
 
-	equals := p.current()
+	equals := p.previous()
 	value, err := p.assignment()
 	if err != nil {
 		return nil, err
@@ -541,7 +567,7 @@ func (p *Parser) factor() (Expression, error) {
 
 	for p.current().OneOf(SLASH, STAR) {
 		operator := p.advance()
-		right, err := p.factor()
+		right, err := p.unary()
 		if err != nil {
 			return nil, err
 		}
@@ -577,7 +603,7 @@ func (p *Parser) call() (Expression, error) {
 		if p.match(LEFT_PAREN) {
 			var arguments []Expression
 			if p.match(RIGHT_PAREN) {
-				e = NewCall(e, p.current(), arguments)
+				e = NewCall(e, p.previous(), arguments)
 				continue
 			}
 
@@ -595,7 +621,7 @@ func (p *Parser) call() (Expression, error) {
 				if p.match(COMMA) {
 					continue
 				} else if p.match(RIGHT_PAREN) {
-					e = NewCall(e, p.current(), arguments)
+					e = NewCall(e, p.previous(), arguments)
 					break
 				} else {
 					return nil, UnexpectedToken(p.current(), COMMA, RIGHT_PAREN)
@@ -620,6 +646,16 @@ func (p *Parser) primary() (Expression, error) {
 		return NewLiteral(p.advance().literal), nil
 	}
 
+	if p.current().Is(ERROR) {
+		// The scanner already reported the lexeme, a placeholder lets the parser diagnose the rest
+		p.advance()
+		return NewLiteral(nil), nil
+	}
+
+	if p.current().Is(INTERPOLATION) {
+		return p.interpolation()
+	}
+
 	if p.current().Is(THIS) {
 		return NewThis(p.advance()), nil
 	}
@@ -635,7 +671,7 @@ func (p *Parser) primary() (Expression, error) {
 			return nil, err
 		}
 
-		if !p.advance().Is(RIGHT_PAREN) {
+		if !p.match(RIGHT_PAREN) {
 			return nil, UnclosedParenthesisError(p.current())
 		}
 
@@ -644,3 +680,28 @@ func (p *Parser) primary() (Expression, error) {
 
 	return nil, UnhandledTokenError(p.current())
 }
+
+// interpolation parses a string with embedded expressions, the parts of the string are literals
+// interpolation → ( INTERPOLATION expression )+ STRING
+func (p *Parser) interpolation() (Expression, error) {
+	quote := p.current()
+
+	var parts []Expression
+	for p.match(INTERPOLATION) {
+		parts = append(parts, NewLiteral(p.previous().literal))
+
+		e, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, e)
+	}
+
+	// The rest of the string starts at the brace that closes the last expression
+	if !p.current().OneOf(INTERPOLATION, STRING) || !strings.HasPrefix(p.current().lexeme, "}") {
+		return nil, UnexpectedToken(p.current(), RIGHT_BRACE)
+	}
+
+	parts = append(parts, NewLiteral(p.advance().literal))
+	return NewInterpolation(quote, parts), nil
+}
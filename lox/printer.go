@@ -2,6 +2,7 @@ package lox
 
 import (
 	"fmt"
+	"strings"
 )
 
 // NewASTPrinter constructor
@@ -10,44 +11,70 @@ func NewASTPrinter() *ASTPrinter {
 }
 
 // ASTPrinter visitor. Traverses the whole tree and creates a string representation of the tree
+// using S-expressions
 type ASTPrinter struct {
 }
 
+// Print the given expression
+func (p *ASTPrinter) Print(e Expression) (string, error) {
+	v, err := e.Accept(p)
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// PrintProgram prints every statement of the program in its own line
+func (p *ASTPrinter) PrintProgram(stmts []Stmt) (string, error) {
+	var lines []string
+	for _, s := range stmts {
+		v, err := s.Accept(p)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, v.(string))
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
 func (p *ASTPrinter) visitThis(e *This) (interface{}, error) {
-	panic("implement me")
+	return e.keyword.lexeme, nil
 }
 
 func (p *ASTPrinter) visitSet(e *Set) (interface{}, error) {
-	panic("implement me")
+	return p.parenthesize("=", fmt.Sprintf("(. %s)", p.nameOf(e.object, e.name)), e.value)
 }
 
 func (p *ASTPrinter) visitGet(e *Get) (interface{}, error) {
-	panic("implement me")
+	return p.parenthesize(".", e.object, e.name.lexeme)
 }
 
 func (p *ASTPrinter) visitCall(e *Call) (interface{}, error) {
-	panic("implement me")
+	parts := []interface{}{e.callee}
+	for _, argument := range e.arguments {
+		parts = append(parts, argument)
+	}
+	return p.parenthesize("call", parts...)
+}
+
+func (p *ASTPrinter) visitInterpolation(e *Interpolation) (interface{}, error) {
+	var parts []interface{}
+	for _, part := range e.parts {
+		parts = append(parts, part)
+	}
+	return p.parenthesize("interpolation", parts...)
 }
 
 func (p *ASTPrinter) visitLogical(e *Logical) (interface{}, error) {
-	panic("implement me")
+	return p.parenthesize(e.operator.lexeme, e.left, e.right)
 }
 
 func (p *ASTPrinter) visitAssign(e *Assign) (interface{}, error) {
-	panic("implement me")
+	return p.parenthesize("=", e.name.lexeme, e.value)
 }
 
 func (p *ASTPrinter) visitVariable(e *Variable) (interface{}, error) {
-	panic("implement me")
-}
-
-// Print the given expression
-func (p *ASTPrinter) Print(e Expression) (string, error) {
-	v, err := e.Accept(p)
-	if err != nil {
-		return "", err
-	}
-	return v.(string), nil
+	return e.token.lexeme, nil
 }
 
 func (p *ASTPrinter) visitBinary(e *Binary) (interface{}, error) {
@@ -59,21 +86,127 @@ func (p *ASTPrinter) visitGrouping(e *Grouping) (interface{}, error) {
 }
 
 func (p *ASTPrinter) visitLiteral(e *Literal) (interface{}, error) {
-	if e.value == nil {
-		return "nil", nil
-	}
-	return fmt.Sprintf("%v", e.value), nil
+	return literalString(e.value), nil
 }
 
 func (p *ASTPrinter) visitUnary(e *Unary) (interface{}, error) {
 	return p.parenthesize(e.operator.lexeme, e.right)
 }
 
-func (p *ASTPrinter) parenthesize(name string, expressions ...Expression) (interface{}, error) {
+func (p *ASTPrinter) visitClassStmt(e *ClassStmt) (interface{}, error) {
+	parts := []interface{}{e.name.lexeme}
+	if e.super != nil {
+		parts = append(parts, "<", e.super)
+	}
+	for _, method := range e.methods {
+		parts = append(parts, method)
+	}
+	return p.parenthesize("class", parts...)
+}
+
+func (p *ASTPrinter) visitExpressionStmt(e *ExpressionStmt) (interface{}, error) {
+	return p.parenthesize("expr", e.expression)
+}
+
+func (p *ASTPrinter) visitFunctionStmt(e *FunctionStmt) (interface{}, error) {
+	var params []string
+	for _, param := range e.params {
+		params = append(params, param.lexeme)
+	}
+
+	var parts []interface{}
+	if e.name != nil {
+		parts = append(parts, e.name.lexeme)
+	}
+	parts = append(parts, fmt.Sprintf("(%s)", strings.Join(params, " ")))
+	for _, s := range e.body.statements {
+		parts = append(parts, s)
+	}
+	return p.parenthesize("fun", parts...)
+}
+
+func (p *ASTPrinter) visitVarStmt(e *VarStmt) (interface{}, error) {
+	if e.initializer == nil {
+		return p.parenthesize("var", e.name.lexeme)
+	}
+
+	if s, ok := e.initializer.(*ExpressionStmt); ok {
+		return p.parenthesize("var", e.name.lexeme, s.expression)
+	}
+	return p.parenthesize("var", e.name.lexeme, e.initializer)
+}
+
+func (p *ASTPrinter) visitBlockStmt(e *BlockStmt) (interface{}, error) {
+	var parts []interface{}
+	for _, s := range e.statements {
+		parts = append(parts, s)
+	}
+	return p.parenthesize("block", parts...)
+}
+
+func (p *ASTPrinter) visitIfStmt(e *IfStmt) (interface{}, error) {
+	if e.elseBranch == nil {
+		return p.parenthesize("if", e.expression, e.thenBranch)
+	}
+	return p.parenthesize("if", e.expression, e.thenBranch, e.elseBranch)
+}
+
+func (p *ASTPrinter) visitForStmt(e *ForStmt) (interface{}, error) {
+	parts := []interface{}{"()", "()", "()", e.body}
+	if e.initializer != nil {
+		parts[0] = e.initializer
+	}
+	if e.condition != nil {
+		parts[1] = e.condition
+	}
+	if e.increment != nil {
+		parts[2] = e.increment
+	}
+	return p.parenthesize("for", parts...)
+}
+
+func (p *ASTPrinter) visitPrintStmt(e *PrintStmt) (interface{}, error) {
+	return p.parenthesize("print", e.expression)
+}
+
+func (p *ASTPrinter) visitCircuitBreakStmt(e *CircuitBreakStmt) (interface{}, error) {
+	if e.statement == nil {
+		return p.parenthesize(e.keyword.lexeme)
+	}
+
+	if s, ok := e.statement.(*ExpressionStmt); ok {
+		return p.parenthesize(e.keyword.lexeme, s.expression)
+	}
+	return p.parenthesize(e.keyword.lexeme, e.statement)
+}
+
+// nameOf prints the object followed by the property name
+func (p *ASTPrinter) nameOf(object Expression, name *Token) string {
+	v, err := object.Accept(p)
+	if err != nil {
+		return name.lexeme
+	}
+	return fmt.Sprintf("%s %s", v, name.lexeme)
+}
+
+// parenthesize prints every part in a list. Parts can be expressions, statements or strings that
+// are printed as they are
+func (p *ASTPrinter) parenthesize(name string, parts ...interface{}) (interface{}, error) {
 	line := fmt.Sprintf("(%s", name)
-	for _, e := range expressions {
+	for _, part := range parts {
 		line += " "
-		v, err := e.Accept(p)
+
+		var v interface{}
+		var err error
+		switch n := part.(type) {
+		case Expression:
+			v, err = n.Accept(p)
+		case Stmt:
+			v, err = n.Accept(p)
+		default:
+			v = fmt.Sprintf("%v", n)
+		}
+
 		if err != nil {
 			return nil, err
 		}
@@ -82,3 +215,287 @@ func (p *ASTPrinter) parenthesize(name string, expressions ...Expression) (inter
 	line += ")"
 	return line, nil
 }
+
+func literalString(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// NewTreePrinter constructor
+func NewTreePrinter() *TreePrinter {
+	return &TreePrinter{}
+}
+
+// TreePrinter visitor. Traverses the whole tree and renders it indented, one node per line with
+// its type, its token and the position of the token in the source
+type TreePrinter struct {
+}
+
+// Print the given expression
+func (p *TreePrinter) Print(e Expression) (string, error) {
+	return p.render(e)
+}
+
+// PrintProgram prints the tree of every statement of the program
+func (p *TreePrinter) PrintProgram(stmts []Stmt) (string, error) {
+	var b strings.Builder
+	for _, s := range stmts {
+		v, err := p.render(s)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+func (p *TreePrinter) visitThis(e *This) (interface{}, error) {
+	return p.node("This", e.keyword)
+}
+
+func (p *TreePrinter) visitSet(e *Set) (interface{}, error) {
+	return p.node("Set", e.name, e.object, e.value)
+}
+
+func (p *TreePrinter) visitGet(e *Get) (interface{}, error) {
+	return p.node("Get", e.name, e.object)
+}
+
+func (p *TreePrinter) visitCall(e *Call) (interface{}, error) {
+	callee, err := p.label("Callee", e.callee)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []interface{}{callee}
+	if len(e.arguments) > 0 {
+		var arguments []interface{}
+		for _, argument := range e.arguments {
+			arguments = append(arguments, argument)
+		}
+
+		v, err := p.label("Arguments", arguments...)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, v)
+	}
+
+	return p.node("Call", e.paren, parts...)
+}
+
+func (p *TreePrinter) visitLogical(e *Logical) (interface{}, error) {
+	return p.node("Logical", e.operator, e.left, e.right)
+}
+
+func (p *TreePrinter) visitAssign(e *Assign) (interface{}, error) {
+	return p.node("Assign", e.name, e.value)
+}
+
+func (p *TreePrinter) visitVariable(e *Variable) (interface{}, error) {
+	return p.node("Variable", e.token)
+}
+
+func (p *TreePrinter) visitBinary(e *Binary) (interface{}, error) {
+	return p.node("Binary", e.operator, e.left, e.right)
+}
+
+func (p *TreePrinter) visitGrouping(e *Grouping) (interface{}, error) {
+	return p.node("Grouping", nil, e.expression)
+}
+
+func (p *TreePrinter) visitInterpolation(e *Interpolation) (interface{}, error) {
+	var parts []interface{}
+	for _, part := range e.parts {
+		parts = append(parts, part)
+	}
+	return p.node("Interpolation", e.quote, parts...)
+}
+
+func (p *TreePrinter) visitLiteral(e *Literal) (interface{}, error) {
+	return p.node("Literal "+literalString(e.value), nil)
+}
+
+func (p *TreePrinter) visitUnary(e *Unary) (interface{}, error) {
+	return p.node("Unary", e.operator, e.right)
+}
+
+func (p *TreePrinter) visitClassStmt(e *ClassStmt) (interface{}, error) {
+	var parts []interface{}
+	if e.super != nil {
+		super, err := p.label("Superclass", e.super)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, super)
+	}
+
+	for _, method := range e.methods {
+		parts = append(parts, method)
+	}
+
+	return p.node("Class", e.name, parts...)
+}
+
+func (p *TreePrinter) visitExpressionStmt(e *ExpressionStmt) (interface{}, error) {
+	return p.node("Expression", nil, e.expression)
+}
+
+func (p *TreePrinter) visitFunctionStmt(e *FunctionStmt) (interface{}, error) {
+	var parts []interface{}
+	if len(e.params) > 0 {
+		var params []interface{}
+		for _, param := range e.params {
+			v, err := p.node("Parameter", param)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, v)
+		}
+
+		v, err := p.label("Parameters", params...)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, v)
+	}
+	parts = append(parts, e.body)
+
+	if e.name == nil {
+		return p.node("Lambda", nil, parts...)
+	}
+	return p.node("Function", e.name, parts...)
+}
+
+func (p *TreePrinter) visitVarStmt(e *VarStmt) (interface{}, error) {
+	if e.initializer == nil {
+		return p.node("Var", e.name)
+	}
+	return p.node("Var", e.name, e.initializer)
+}
+
+func (p *TreePrinter) visitBlockStmt(e *BlockStmt) (interface{}, error) {
+	var parts []interface{}
+	for _, s := range e.statements {
+		parts = append(parts, s)
+	}
+	return p.node("Block", nil, parts...)
+}
+
+func (p *TreePrinter) visitIfStmt(e *IfStmt) (interface{}, error) {
+	condition, err := p.label("Condition", e.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	then, err := p.label("Then", e.thenBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []interface{}{condition, then}
+	if e.elseBranch != nil {
+		v, err := p.label("Else", e.elseBranch)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, v)
+	}
+
+	return p.node("If", e.keyword, parts...)
+}
+
+func (p *TreePrinter) visitForStmt(e *ForStmt) (interface{}, error) {
+	var parts []interface{}
+	clauses := []struct {
+		name string
+		node interface{}
+	}{
+		{"Initializer", e.initializer},
+		{"Condition", e.condition},
+		{"Increment", e.increment},
+	}
+
+	for _, clause := range clauses {
+		if clause.node == nil {
+			continue
+		}
+
+		v, err := p.label(clause.name, clause.node)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, v)
+	}
+
+	body, err := p.label("Body", e.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.node("For", e.keyword, append(parts, body)...)
+}
+
+func (p *TreePrinter) visitPrintStmt(e *PrintStmt) (interface{}, error) {
+	return p.node("Print", e.keyword, e.expression)
+}
+
+func (p *TreePrinter) visitCircuitBreakStmt(e *CircuitBreakStmt) (interface{}, error) {
+	name := strings.ToUpper(e.keyword.lexeme[:1]) + e.keyword.lexeme[1:]
+	if e.statement == nil {
+		return p.node(name, e.keyword)
+	}
+	return p.node(name, e.keyword, e.statement)
+}
+
+// render a node or return it as it is when it is already rendered
+func (p *TreePrinter) render(node interface{}) (string, error) {
+	var v interface{}
+	var err error
+	switch n := node.(type) {
+	case Expression:
+		v, err = n.Accept(p)
+	case Stmt:
+		v, err = n.Accept(p)
+	default:
+		v = n
+	}
+
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// node renders a header line with the name of the node and its token followed by every child
+// indented one level
+func (p *TreePrinter) node(name string, t *Token, children ...interface{}) (string, error) {
+	header := name
+	if t != nil {
+		header = fmt.Sprintf("%s '%s' @%v:%v", name, t.lexeme, t.line, t.column)
+	}
+	return p.label(header, children...)
+}
+
+// label renders a line with the given text followed by every child indented one level
+func (p *TreePrinter) label(text string, children ...interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteString(text + "\n")
+	for _, child := range children {
+		v, err := p.render(child)
+		if err != nil {
+			return "", err
+		}
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(v, "\n"), "\n") {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -8,10 +8,10 @@ import (
 func TestASTPrinter_Print(t *testing.T) {
 	e := lox.NewBinary(
 		lox.NewUnary(
-			lox.NewToken(lox.MINUS, "-", nil, 1),
+			lox.NewToken(lox.MINUS, "-", nil, 1, 1),
 			lox.NewLiteral(123),
 		),
-		lox.NewToken(lox.STAR, "*", nil, 1),
+		lox.NewToken(lox.STAR, "*", nil, 1, 3),
 		lox.NewGrouping(lox.NewLiteral(45.67)),
 	)
 
@@ -24,3 +24,40 @@ func TestASTPrinter_Print(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestASTPrinter_PrintProgram(t *testing.T) {
+	stmts := parse(t, "fun double(n) { return n * 2; }\nfor var i = 0; i < 2; i = i + 1 { print double(i); }")
+
+	res, err := lox.NewASTPrinter().PrintProgram(stmts)
+	if err != nil {
+		t.FailNow()
+	}
+
+	expected := "(block (fun double (n) (return (* n 2))) " +
+		"(for (var i 0) (< i 2) (= i (+ i 1)) (block (print (call double i)))))\n"
+	if res != expected {
+		t.Fatalf("expected %q, got %q", expected, res)
+	}
+}
+
+func TestTreePrinter_PrintProgram(t *testing.T) {
+	stmts := parse(t, "if ok {\n  print \"yes\";\n}")
+
+	res, err := lox.NewTreePrinter().PrintProgram(stmts)
+	if err != nil {
+		t.FailNow()
+	}
+
+	expected := `Block
+  If 'if' @1:1
+    Condition
+      Variable 'ok' @1:4
+    Then
+      Block
+        Print 'print' @2:3
+          Literal "yes"
+`
+	if res != expected {
+		t.Fatalf("expected %q, got %q", expected, res)
+	}
+}
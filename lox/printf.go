@@ -0,0 +1,183 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxWidth of a formatted value, wider ones are surely a mistake and would allocate huge strings
+const maxWidth = 1000000
+
+// NewFormatFunction constructor
+func NewFormatFunction() *NativeFunction {
+	// format returns the arguments formatted as the verbs of the first one describe
+	return NewNativeFunction(1, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		layout, err := stringArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := i.sprintf(paren, layout, arguments[1:])
+		if err != nil {
+			return nil, err
+		}
+		return i.newString(s), nil
+	})
+}
+
+// NewPrintfFunction constructor
+func NewPrintfFunction() *NativeFunction {
+	// printf writes the arguments formatted as format does, without adding a new line
+	return NewNativeFunction(1, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		layout, err := stringArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := i.sprintf(paren, layout, arguments[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprint(i.output, s)
+		return nil, nil
+	})
+}
+
+// verb of a format with its flags, width and precision, as %-8.2f
+type verb struct {
+	flags     string
+	width     int
+	precision int
+	verb      byte
+}
+
+// sprintf formats the arguments following the verbs of a layout, as Go's fmt does:
+//
+//	%v the value as print shows it    %s the same, the precision truncates it
+//	%q strings between quotes          %d integers
+//	%f %e %g floating point numbers    %x %X %o %b integers in other bases
+//	%% a percent sign
+//
+// Between the percent sign and the verb go the flags '-' to align to the left, '0' to pad numbers
+// with zeros, '+' and ' ' to show the sign of positive numbers, then the width and a precision
+// after a dot. The result is checked against the memory limit while it is built, since wide verbs
+// make it much longer than the layout and the arguments.
+func (i *Interpreter) sprintf(paren *Token, layout string, arguments []interface{}) (string, error) {
+	var b strings.Builder
+	next := 0
+
+	for n := 0; n < len(layout); n++ {
+		if layout[n] != '%' {
+			b.WriteByte(layout[n])
+			continue
+		}
+
+		v, end, err := parseVerb(paren, layout, n+1)
+		if err != nil {
+			return "", err
+		}
+		n = end
+
+		if v.verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+
+		if next >= len(arguments) {
+			return "", InvalidFormat(paren, layout, fmt.Sprintf("missing argument for %%%c", v.verb))
+		}
+
+		s, err := v.format(paren, layout, arguments[next])
+		if err != nil {
+			return "", err
+		}
+
+		if !i.fits(b.Len() + len(s)) {
+			return "", MemoryLimitExceeded(paren, "bytes", i.memoryLimit)
+		}
+		b.WriteString(s)
+		next++
+	}
+
+	if next < len(arguments) {
+		return "", InvalidFormat(paren, layout, fmt.Sprintf("%d arguments but %d verbs", len(arguments), next))
+	}
+	return b.String(), nil
+}
+
+// parseVerb starting after its percent sign, it returns the position of the verb character
+func parseVerb(paren *Token, layout string, n int) (*verb, int, error) {
+	v := &verb{precision: -1}
+	for ; n < len(layout) && strings.IndexByte("-+0 ", layout[n]) >= 0; n++ {
+		v.flags += string(layout[n])
+	}
+
+	var err error
+	if v.width, n, err = parseNumber(paren, layout, n); err != nil {
+		return nil, 0, err
+	}
+
+	if n < len(layout) && layout[n] == '.' {
+		if v.precision, n, err = parseNumber(paren, layout, n+1); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if n >= len(layout) {
+		return nil, 0, InvalidFormat(paren, layout, "missing verb at the end")
+	}
+	v.verb = layout[n]
+	return v, n, nil
+}
+
+// parseNumber of a width or a precision, it is 0 when there are no digits
+func parseNumber(paren *Token, layout string, n int) (int, int, error) {
+	x := 0
+	for ; n < len(layout) && isDigit(rune(layout[n])); n++ {
+		x = x*10 + int(layout[n]-'0')
+		if x > maxWidth {
+			return 0, 0, InvalidFormat(paren, layout, fmt.Sprintf("width or precision larger than %d", maxWidth))
+		}
+	}
+	return x, n, nil
+}
+
+// format a value with the verb
+func (v *verb) format(paren *Token, layout string, value interface{}) (string, error) {
+	switch v.verb {
+	case 'v', 's':
+		return fmt.Sprintf(v.layout('s'), Stringify(value)), nil
+	case 'q':
+		return fmt.Sprintf(v.layout('s'), quote(value)), nil
+	case 'f', 'e', 'E', 'g', 'G':
+		x, ok := value.(float64)
+		if !ok {
+			return "", InvalidDataTypeError(paren, getDataType(value), number)
+		}
+		return fmt.Sprintf(v.layout(v.verb), x), nil
+	case 'd', 'x', 'X', 'o', 'b':
+		x, ok := value.(float64)
+		if !ok {
+			return "", InvalidDataTypeError(paren, getDataType(value), number)
+		}
+		if x != math.Trunc(x) || math.IsInf(x, 0) || math.Abs(x) > 1<<53 {
+			return "", InvalidFormat(paren, layout, fmt.Sprintf("%%%c needs an integer, got %v", v.verb, x))
+		}
+		return fmt.Sprintf(v.layout(v.verb), int64(x)), nil
+	}
+	return "", InvalidFormat(paren, layout, fmt.Sprintf("unknown verb %%%c", v.verb))
+}
+
+// layout of the verb for Go's fmt
+func (v *verb) layout(verb byte) string {
+	layout := "%" + v.flags
+	if v.width > 0 {
+		layout += fmt.Sprint(v.width)
+	}
+	if v.precision >= 0 {
+		layout += "." + fmt.Sprint(v.precision)
+	}
+	return layout + string(verb)
+}
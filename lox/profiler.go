@@ -0,0 +1,361 @@
+package lox
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// NewProfiler constructor
+func NewProfiler() *Profiler {
+	return &Profiler{
+		now:       time.Now,
+		samples:   map[string]*sample{},
+		functions: map[funcID]*FunctionProfile{},
+		active:    map[funcID]int{},
+		entered:   map[funcID]time.Time{},
+	}
+}
+
+// Profiler measures where a program spends its time. Instead of sampling, it instruments the
+// interpreter: the time between two statements, calls or returns is attributed to the call stack
+// that was running, including the line every frame was at.
+type Profiler struct {
+	interpreter *Interpreter
+	now         func() time.Time
+	start, last time.Time
+	stopped     bool
+	// samples by call stack, order keeps the stacks in the order they were found
+	samples   map[string]*sample
+	order     []string
+	functions map[funcID]*FunctionProfile
+	// active counts the invocations in progress of every function, entered is when the outermost
+	// one started so that recursive calls are not counted twice in the total time
+	active  map[funcID]int
+	entered map[funcID]time.Time
+}
+
+// funcID identifies a function, names alone are not unique since methods of different classes
+// and nested functions may share them
+type funcID struct {
+	name        string
+	declaration Position
+}
+
+// location of a call stack, a line of a function
+type location struct {
+	function funcID
+	line     int
+}
+
+type sample struct {
+	// stack of locations, the innermost first
+	stack []location
+	calls int64
+	time  time.Duration
+}
+
+// FunctionProfile is the time spent in a function and the amount of times it was called
+type FunctionProfile struct {
+	Name        string
+	Declaration Position
+	Calls       int
+	// Self is the time spent in the function itself, Total includes the functions it called
+	Self  time.Duration
+	Total time.Duration
+}
+
+func frameFunction(f *Frame) funcID {
+	return funcID{name: f.Name, declaration: f.Declaration}
+}
+
+func (p *Profiler) function(f funcID) *FunctionProfile {
+	fp, ok := p.functions[f]
+	if !ok {
+		fp = &FunctionProfile{Name: f.name, Declaration: f.declaration}
+		p.functions[f] = fp
+	}
+	return fp
+}
+
+// sample of the stack formed by the frames and the frame being pushed, if any
+func (p *Profiler) sample(frames []*Frame, pushed *Frame) *sample {
+	var stack []location
+	if pushed != nil {
+		stack = append(stack, location{function: frameFunction(pushed), line: pushed.Declaration.Line})
+	}
+	for i := len(frames) - 1; i >= 0; i-- {
+		// before its first statement a frame is binding the arguments, at the declaration
+		line := frames[i].Position.Line
+		if line == 0 {
+			line = frames[i].Declaration.Line
+		}
+		stack = append(stack, location{function: frameFunction(frames[i]), line: line})
+	}
+
+	var key strings.Builder
+	for _, l := range stack {
+		fmt.Fprintf(&key, "%s:%d:%d:%d;", l.function.name, l.function.declaration.Line, l.function.declaration.Column, l.line)
+	}
+
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &sample{stack: stack}
+		p.samples[key.String()] = s
+		p.order = append(p.order, key.String())
+	}
+	return s
+}
+
+// tick attributes the time since the last event to the current stack
+func (p *Profiler) tick(frames []*Frame) {
+	now := p.now()
+	elapsed := now.Sub(p.last)
+	p.last = now
+
+	if len(frames) == 0 || p.stopped {
+		return
+	}
+
+	p.sample(frames, nil).time += elapsed
+	p.function(frameFunction(frames[len(frames)-1])).Self += elapsed
+}
+
+// enter is called before pushing the frame of a call
+func (p *Profiler) enter(frames []*Frame, f *Frame) {
+	if len(frames) == 0 {
+		p.start = p.now()
+		p.last = p.start
+	}
+	p.tick(frames)
+
+	fn := frameFunction(f)
+	p.function(fn).Calls++
+	p.sample(frames, f).calls++
+
+	if p.active[fn] == 0 {
+		p.entered[fn] = p.last
+	}
+	p.active[fn]++
+}
+
+// exit is called before popping the frame of a call
+func (p *Profiler) exit(frames []*Frame) {
+	p.tick(frames)
+	p.close(frames[len(frames)-1])
+}
+
+func (p *Profiler) close(f *Frame) {
+	fn := frameFunction(f)
+	p.active[fn]--
+	if p.active[fn] == 0 {
+		p.function(fn).Total += p.last.Sub(p.entered[fn])
+	}
+}
+
+// Stop profiling, the frames still in the stack are closed as if they returned
+func (p *Profiler) Stop() {
+	if p.stopped || p.interpreter == nil {
+		return
+	}
+
+	frames := p.interpreter.frames
+	p.tick(frames)
+	for i := len(frames) - 1; i >= 0; i-- {
+		p.close(frames[i])
+	}
+	p.stopped = true
+}
+
+// Functions sorted by total time, the slowest first
+func (p *Profiler) Functions() []FunctionProfile {
+	var functions []FunctionProfile
+	for _, fp := range p.functions {
+		functions = append(functions, *fp)
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Total != functions[j].Total {
+			return functions[i].Total > functions[j].Total
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
+
+// Report writes a table with the calls, self and total time of every function
+func (p *Profiler) Report(w io.Writer, filename string) error {
+	p.Stop()
+
+	fmt.Fprintf(w, "Total time: %v\n", p.last.Sub(p.start).Round(time.Microsecond))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "calls\tself\ttotal\t\t")
+	for _, f := range p.Functions() {
+		name := f.Name
+		if f.Declaration.Line > 0 {
+			name = fmt.Sprintf("%s (%s:%d)", f.Name, filename, f.Declaration.Line)
+		}
+		fmt.Fprintf(tw, "%d\t%v\t%v\t\t%s\n", f.Calls, f.Self.Round(time.Microsecond), f.Total.Round(time.Microsecond), name)
+	}
+	return tw.Flush()
+}
+
+// WriteProfile writes the profile gzipped in the protocol buffer format of pprof. Its locations
+// are the lines of the functions of the program, found in the given file.
+func (p *Profiler) WriteProfile(w io.Writer, filename string) error {
+	p.Stop()
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(p.encode(filename)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// encode the profile as described by
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+func (p *Profiler) encode(filename string) []byte {
+	strs := &stringTable{index: map[string]int64{}}
+	strs.add("")
+
+	var out protobuf
+	valueType := func(field int, typ, unit string) {
+		var vt protobuf
+		vt.int64(1, strs.add(typ))
+		vt.int64(2, strs.add(unit))
+		out.message(field, &vt)
+	}
+	valueType(1, "calls", "count")
+	valueType(1, "time", "nanoseconds")
+
+	functionIDs := map[funcID]uint64{}
+	locationIDs := map[location]uint64{}
+	var functions, locations protobuf
+
+	for _, key := range p.order {
+		s := p.samples[key]
+		if s.calls == 0 && s.time == 0 {
+			continue
+		}
+
+		ids := make([]uint64, len(s.stack))
+		for i, l := range s.stack {
+			fid, ok := functionIDs[l.function]
+			if !ok {
+				fid = uint64(len(functionIDs) + 1)
+				functionIDs[l.function] = fid
+
+				var f protobuf
+				f.uint64(1, fid)
+				f.int64(2, strs.add(l.function.name))
+				f.int64(3, strs.add(l.function.name))
+				f.int64(4, strs.add(filename))
+				f.int64(5, int64(l.function.declaration.Line))
+				functions.message(5, &f)
+			}
+
+			lid, ok := locationIDs[l]
+			if !ok {
+				lid = uint64(len(locationIDs) + 1)
+				locationIDs[l] = lid
+
+				var line, loc protobuf
+				line.uint64(1, fid)
+				line.int64(2, int64(l.line))
+				loc.uint64(1, lid)
+				loc.message(4, &line)
+				locations.message(4, &loc)
+			}
+			ids[i] = lid
+		}
+
+		var sm protobuf
+		sm.packed(1, ids)
+		sm.packed(2, []uint64{uint64(s.calls), uint64(s.time.Nanoseconds())})
+		out.message(2, &sm)
+	}
+
+	out.b = append(out.b, locations.b...)
+	out.b = append(out.b, functions.b...)
+
+	var period protobuf
+	period.int64(1, strs.add("time"))
+	period.int64(2, strs.add("nanoseconds"))
+	defaultType := strs.add("time")
+
+	for _, s := range strs.strings {
+		out.bytes(6, []byte(s))
+	}
+	out.int64(9, p.start.UnixNano())
+	out.int64(10, p.last.Sub(p.start).Nanoseconds())
+	out.message(11, &period)
+	out.int64(12, 1)
+	out.int64(14, defaultType)
+	return out.b
+}
+
+type stringTable struct {
+	strings []string
+	index   map[string]int64
+}
+
+func (t *stringTable) add(s string) int64 {
+	if i, ok := t.index[s]; ok {
+		return i
+	}
+	t.index[s] = int64(len(t.strings))
+	t.strings = append(t.strings, s)
+	return t.index[s]
+}
+
+// protobuf encodes the wire format of protocol buffers, fields with zero values are left out
+// except for bytes
+type protobuf struct {
+	b []byte
+}
+
+func (p *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		p.b = append(p.b, byte(x)|0x80)
+		x >>= 7
+	}
+	p.b = append(p.b, byte(x))
+}
+
+func (p *protobuf) tag(field int, wireType uint64) {
+	p.varint(uint64(field)<<3 | wireType)
+}
+
+func (p *protobuf) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	p.tag(field, 0)
+	p.varint(x)
+}
+
+func (p *protobuf) int64(field int, x int64) {
+	p.uint64(field, uint64(x))
+}
+
+func (p *protobuf) bytes(field int, b []byte) {
+	p.tag(field, 2)
+	p.varint(uint64(len(b)))
+	p.b = append(p.b, b...)
+}
+
+func (p *protobuf) message(field int, m *protobuf) {
+	p.bytes(field, m.b)
+}
+
+func (p *protobuf) packed(field int, xs []uint64) {
+	var values protobuf
+	for _, x := range xs {
+		values.varint(x)
+	}
+	p.bytes(field, values.b)
+}
@@ -0,0 +1,71 @@
+package lox_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"golox/lox"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	p := lox.NewProfiler()
+	_, err := interpret(t, `fun square(n) {
+    return n * n;
+}
+
+fun sum(n) {
+    var total = 0;
+    for var i = 0; i < n; i = i + 1 {
+        total = total + square(i);
+    }
+    return total;
+}
+
+print sum(10);
+`, lox.WithProfiler(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report bytes.Buffer
+	if err := p.Report(&report, "sum.lox"); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := map[string]int{}
+	for _, f := range p.Functions() {
+		calls[f.Name] = f.Calls
+		if f.Self > f.Total {
+			t.Fatalf("%s spent more time by itself than in total: %+v", f.Name, f)
+		}
+	}
+	if calls["script"] != 1 || calls["sum"] != 1 || calls["square"] != 10 {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+
+	if !strings.Contains(report.String(), "square (sum.lox:1)") {
+		t.Fatalf("the report is missing square:\n%s", report.String())
+	}
+
+	var out bytes.Buffer
+	if err := p.WriteProfile(&out, "sum.lox"); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"square", "sum", "script", "sum.lox", "nanoseconds"} {
+		if !bytes.Contains(profile, []byte(s)) {
+			t.Fatalf("the profile is missing %q", s)
+		}
+	}
+}
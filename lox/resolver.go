@@ -1,10 +1,13 @@
 package lox
 
+import "sort"
+
 // NewResolver constructor
 func NewResolver(i *Interpreter) *Resolver {
 	return &Resolver{
 		interpreter: i,
 		scopes:      NewScopeStack(),
+		declared:    map[string]bool{},
 	}
 }
 
@@ -15,10 +18,74 @@ type Resolver struct {
 	interpreter *Interpreter
 	scopes      *ScopeStack
 	inClass     bool
+	// declared holds the name of every variable, function and class declared in the program
+	declared map[string]bool
+	// globals holds the variables that could not be resolved to a local scope
+	globals []*Token
+	// unused holds the variables that were never used. They don't stop the resolution, so they are
+	// reported once the whole program is resolved.
+	unused []*Token
+	// ends holds the closing brace of every scope in the stack, nil when a scope lasts until the
+	// end of the source
+	ends []*Token
+	// analysis records the symbols of the program when it is being analyzed, nil otherwise
+	analysis *Analysis
 }
 
 // Resolve API
 func (r *Resolver) Resolve(stmts []Stmt) (interface{}, error) {
+	v, err := r.resolve(stmts)
+	if errs := r.errors(err); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return v, nil
+}
+
+// errors raised while resolving the program. A misspelled variable usually leaves its intended
+// target unused, so undeclared variables are reported first since they are the root cause.
+func (r *Resolver) errors(err error) []error {
+	errs := r.checkGlobals()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	sort.SliceStable(r.unused, func(i, j int) bool {
+		a, b := r.unused[i], r.unused[j]
+		return a.line < b.line || (a.line == b.line && a.column < b.column)
+	})
+	for _, t := range r.unused {
+		errs = append(errs, UnusedVariable(t))
+	}
+
+	return errs
+}
+
+// checkGlobals verifies that every variable that is not local is either a native or is declared
+// somewhere in the program, which means it may be defined by the time it is accessed.
+func (r *Resolver) checkGlobals() []error {
+	var errs []error
+	for _, t := range r.globals {
+		if r.declared[t.lexeme] {
+			continue
+		}
+
+		if _, ok := r.interpreter.globals.get(t.lexeme); ok {
+			continue
+		}
+
+		candidates := r.interpreter.globals.names()
+		for name := range r.declared {
+			candidates = append(candidates, name)
+		}
+
+		errs = append(errs, UndeclaredVariable(t, closestName(t.lexeme, candidates)))
+	}
+
+	return errs
+}
+
+func (r *Resolver) resolve(stmts []Stmt) (interface{}, error) {
 	for _, s := range stmts {
 		_, err := r.resolveStatement(s)
 		if err != nil {
@@ -48,61 +115,75 @@ func (r *Resolver) resolveLocal(e Expression, name *Token) (interface{}, error)
 		if ok {
 			r.interpreter.Resolve(e, r.scopes.Size()-i-1)
 			v.used = true
-			break
+			r.analysis.reference(name, v.symbol)
+			return nil, nil
 		}
 	}
 
+	if _, ok := e.(*This); !ok {
+		r.globals = append(r.globals, name)
+		r.analysis.unresolved(name, false)
+	}
+
 	return nil, nil
 }
 
-func (r *Resolver) resolveFunction(s *FunctionStmt) (interface{}, error) {
-	r.beginScope()
+// resolveFunction resolves the parameters and body of a function. Its symbol, if any, contains
+// the symbols declared inside of it.
+func (r *Resolver) resolveFunction(s *FunctionStmt, symbol *Symbol) (interface{}, error) {
+	r.analysis.enter(symbol)
+	defer r.analysis.leave(symbol)
+
+	r.beginScope(s.body.brace)
 	for _, param := range s.params {
-		r.declare(param)
+		r.declare(param, ParameterSymbol)
 		r.define(param)
 	}
 
-	v, err := r.Resolve(s.body.statements)
+	v, err := r.resolve(s.body.statements)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.endScope(); err != nil {
-		return nil, err
-	}
-
+	r.endScope()
 	return v, nil
 }
 
-func (r *Resolver) beginScope() map[string]*ScopeEntry {
+// beginScope pushes a new scope that ends at the given closing brace
+func (r *Resolver) beginScope(end *Token) map[string]*ScopeEntry {
 	scope := map[string]*ScopeEntry{}
 	r.scopes.Push(scope)
+	r.ends = append(r.ends, end)
 	return scope
 }
 
-func (r *Resolver) endScope() error {
+func (r *Resolver) endScope() {
 	s, err := r.scopes.Pop()
 	if err != nil {
-		return nil
+		return
 	}
 
+	end := r.ends[len(r.ends)-1]
+	r.ends = r.ends[:len(r.ends)-1]
+
 	for _, entry := range s {
 		if !entry.used {
-			return UnusedVariable(entry.token)
+			r.unused = append(r.unused, entry.token)
 		}
+		r.analysis.end(entry.symbol, end)
 	}
-
-	return nil
 }
 
-func (r *Resolver) declare(t *Token) {
+func (r *Resolver) declare(t *Token, kind SymbolKind) *Symbol {
 	s, err := r.scopes.Peek()
 	if err != nil {
-		return
+		return nil
 	}
 
-	s[t.lexeme] = &ScopeEntry{token: t}
-	return
+	symbol := r.analysis.declare(t, kind)
+	s[t.lexeme] = &ScopeEntry{token: t, symbol: symbol}
+	r.declared[t.lexeme] = true
+	return symbol
 }
 
 func (r *Resolver) define(t *Token) {
@@ -144,6 +225,16 @@ func (r *Resolver) visitGrouping(e *Grouping) (interface{}, error) {
 	return r.resolveExpression(e.expression)
 }
 
+func (r *Resolver) visitInterpolation(e *Interpolation) (interface{}, error) {
+	for _, part := range e.parts {
+		_, err := r.resolveExpression(part)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitLogical(e *Logical) (interface{}, error) {
 	_, err := r.resolveExpression(e.left)
 	if err != nil {
@@ -182,10 +273,17 @@ func (r *Resolver) visitAssign(e *Assign) (interface{}, error) {
 }
 
 func (r *Resolver) visitGet(e *Get) (interface{}, error) {
+	r.analysis.unresolved(e.name, true)
 	return r.resolveExpression(e.object)
 }
 
 func (r *Resolver) visitSet(e *Set) (interface{}, error) {
+	if _, ok := e.object.(*This); ok && r.inClass {
+		r.analysis.field(e.name)
+	} else {
+		r.analysis.unresolved(e.name, true)
+	}
+
 	_, err := r.resolveExpression(e.value)
 	if err != nil {
 		return nil, err
@@ -206,7 +304,7 @@ func (r *Resolver) visitIfStmt(e *IfStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	_, err = r.Resolve(e.thenBranch.statements)
+	_, err = r.resolveStatement(e.thenBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -215,11 +313,11 @@ func (r *Resolver) visitIfStmt(e *IfStmt) (interface{}, error) {
 		return nil, nil
 	}
 
-	return r.Resolve(e.elseBranch.statements)
+	return r.resolveStatement(e.elseBranch)
 }
 
 func (r *Resolver) visitForStmt(e *ForStmt) (interface{}, error) {
-	r.beginScope()
+	r.beginScope(e.body.brace)
 	if e.initializer != nil {
 		_, err := r.resolveStatement(e.initializer)
 		if err != nil {
@@ -227,20 +325,26 @@ func (r *Resolver) visitForStmt(e *ForStmt) (interface{}, error) {
 		}
 	}
 
-	_, err := r.resolveExpression(e.condition)
-	if err != nil {
-		return nil, err
+	if e.condition != nil {
+		_, err := r.resolveExpression(e.condition)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	v, err := r.Resolve(e.body.statements)
-	if err != nil {
-		return nil, err
+	if e.increment != nil {
+		_, err := r.resolveExpression(e.increment)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if err := r.endScope(); err != nil {
+	v, err := r.resolve(e.body.statements)
+	if err != nil {
 		return nil, err
 	}
 
+	r.endScope()
 	return v, nil
 }
 
@@ -258,7 +362,7 @@ func (r *Resolver) visitVarStmt(e *VarStmt) (interface{}, error) {
 		return nil, VariableAlreadyDeclared(e.name)
 	}
 
-	r.declare(e.name)
+	r.declare(e.name, VariableSymbol)
 	if e.initializer != nil {
 		_, err := r.resolveStatement(e.initializer)
 		if err != nil {
@@ -270,17 +374,14 @@ func (r *Resolver) visitVarStmt(e *VarStmt) (interface{}, error) {
 }
 
 func (r *Resolver) visitBlockStmt(e *BlockStmt) (interface{}, error) {
-	r.beginScope()
+	r.beginScope(e.brace)
 
-	v, err := r.Resolve(e.statements)
+	v, err := r.resolve(e.statements)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.endScope(); err != nil {
-		return nil, err
-	}
-
+	r.endScope()
 	return v, nil
 }
 
@@ -296,17 +397,19 @@ func (r *Resolver) visitExpressionStmt(e *ExpressionStmt) (interface{}, error) {
 }
 
 func (r *Resolver) visitFunctionStmt(e *FunctionStmt) (interface{}, error) {
+	var symbol *Symbol
 	if e.name != nil {
 		// If is not a lambda function
-		r.declare(e.name)
+		symbol = r.declare(e.name, FunctionSymbol)
 		r.define(e.name)
 	}
 
-	return r.resolveFunction(e)
+	r.analysis.signature(symbol, "fun", e.params)
+	return r.resolveFunction(e, symbol)
 }
 
 func (r *Resolver) visitClassStmt(e *ClassStmt) (interface{}, error) {
-	r.declare(e.name)
+	class := r.declare(e.name, ClassSymbol)
 	r.define(e.name)
 
 	if e.super != nil {
@@ -320,22 +423,25 @@ func (r *Resolver) visitClassStmt(e *ClassStmt) (interface{}, error) {
 		}
 	}
 
-	r.beginScope()
+	r.analysis.inherits(class, e.super)
+	r.analysis.enter(class)
+	defer r.analysis.leave(class)
+
+	r.beginScope(nil)
 	r.inClass = true
 	defer func() {
 		r.inClass = false
 	}()
 
 	for _, method := range e.methods {
-		_, err := r.resolveFunction(method)
+		symbol := r.analysis.method(method.name)
+		r.analysis.signature(symbol, "method", method.params)
+		_, err := r.resolveFunction(method, symbol)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if err := r.endScope(); err != nil {
-		return nil, err
-	}
-
+	r.endScope()
 	return nil, nil
 }
@@ -2,6 +2,8 @@ package lox
 
 import (
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 func NewScanner(source string) *Scanner {
@@ -14,23 +16,60 @@ func NewScanner(source string) *Scanner {
 type Scanner struct {
 	tokens   []*Token
 	iterator *Iterator
+	// comments makes the scanner emit COMMENT tokens instead of discarding them
+	comments bool
+	// interpolations are the strings whose embedded expressions are being scanned, the innermost last
+	interpolations []*interpolation
 }
 
-func (s *Scanner) ScanTokens() ([]*Token, error) {
+// interpolation is a string with an embedded expression being scanned
+type interpolation struct {
+	// line and column where the string starts
+	line   int
+	column int
+	// braces opened in the expression and not closed yet, the string resumes at the brace that
+	// closes the expression
+	braces int
+}
+
+// WithComments makes the scanner emit a COMMENT token for every comment in the source. The parser
+// does not accept them, they are meant for tools that need to preserve the comments like the formatter.
+func (s *Scanner) WithComments() *Scanner {
+	s.comments = true
+	return s
+}
+
+// ScanTokens scans the whole source. When a lexeme can not be scanned, the error is recorded, an ERROR
+// token is emitted in its place and the scanner keeps going, so every lexical error in the source
+// is returned together with the tokens.
+func (s *Scanner) ScanTokens() ([]*Token, []error) {
 	if len(s.iterator.source) == 0 {
 		return []*Token{}, nil
 	}
 
+	var errs []error
 	for !(s.iterator.isAtEnd()) {
-		s.iterator.start = s.iterator.current
+		s.iterator.startLexeme()
 		if err := s.scanToken(); err != nil {
-			report(s.iterator.line, "", err.Error())
-			return nil, err
+			errs = append(errs, err)
+			s.addToken(ERROR, err)
 		}
 	}
 
+	s.iterator.startLexeme()
+	if len(s.interpolations) > 0 {
+		err := UnterminatedStringError(s.interpolations[0].line, s.interpolations[0].column)
+		errs = append(errs, err)
+		s.addToken(ERROR, err)
+	}
+
 	s.addTokenByType(EOF)
-	return s.tokens, nil
+	return s.tokens, errs
+}
+
+// unexpected raises an error for the current lexeme
+func (s *Scanner) unexpected() error {
+	return UnexpectedLexeme(s.iterator.currentLexeme(), s.iterator.startLine, s.iterator.startColumn)
 }
 
 func (s *Scanner) scanToken() error {
@@ -44,9 +83,20 @@ func (s *Scanner) scanToken() error {
 		s.addTokenByType(RIGHT_PAREN)
 		break
 	case '{':
+		if len(s.interpolations) > 0 {
+			s.interpolations[len(s.interpolations)-1].braces++
+		}
 		s.addTokenByType(LEFT_BRACE)
 		break
 	case '}':
+		if n := len(s.interpolations); n > 0 {
+			in := s.interpolations[n-1]
+			if in.braces == 0 {
+				s.interpolations = s.interpolations[:n-1]
+				return s.stringPart(in.line, in.column)
+			}
+			in.braces--
+		}
 		s.addTokenByType(RIGHT_BRACE)
 		break
 	case ',':
@@ -66,7 +116,7 @@ func (s *Scanner) scanToken() error {
 		break
 	case '*':
 		if s.iterator.match('/') {
-			return UnexpectedLexeme(s.iterator.peek(), s.iterator.line, s.iterator.column)
+			return s.unexpected()
 		}
 		s.addTokenByType(STAR)
 		break
@@ -109,13 +159,13 @@ func (s *Scanner) scanToken() error {
 		if s.iterator.match('|') {
 			s.addTokenByType(OR)
 		} else {
-			return UnexpectedLexeme(s.iterator.next(), s.iterator.line, s.iterator.column+1)
+			return s.unexpected()
 		}
 	case '&':
 		if s.iterator.match('&') {
 			s.addTokenByType(AND)
 		} else {
-			return UnexpectedLexeme(s.iterator.next(), s.iterator.line, s.iterator.column+1)
+			return s.unexpected()
 		}
 	case ' ':
 	case '\r':
@@ -123,7 +173,9 @@ func (s *Scanner) scanToken() error {
 	case '\n':
 		break
 	case '"':
-		if err := s.string(); err != nil {
+		return s.stringPart(s.iterator.startLine, s.iterator.startColumn)
+	case '`':
+		if err := s.rawString(); err != nil {
 			return err
 		}
 		break
@@ -138,7 +190,7 @@ func (s *Scanner) scanToken() error {
 			break
 		}
 
-		return UnexpectedLexeme(s.iterator.peek(), s.iterator.line, s.iterator.column)
+		return s.unexpected()
 	}
 
 	return nil
@@ -151,12 +203,17 @@ func (s *Scanner) comment() {
 		}
 	} else if s.iterator.match('*') {
 		for !s.iterator.isAtEnd() {
-			if s.iterator.advance() == '*' && s.iterator.advance() == '/' {
+			if s.iterator.advance() == '*' && s.iterator.match('/') {
 				break
 			}
 		}
 	} else {
 		s.addTokenByType(SLASH)
+		return
+	}
+
+	if s.comments {
+		s.addTokenByType(COMMENT)
 	}
 }
 
@@ -202,19 +259,115 @@ func (s *Scanner) identifier() {
 	s.addToken(token, literal)
 }
 
-func (s *Scanner) string() error {
-	var value string
+// stringPart scans a string literal, which may span several lines, replacing its escape sequences.
+// It stops at the closing quote, or at the ${ that starts an embedded expression in which case it
+// emits an INTERPOLATION token and the expression is scanned as any other code until the brace
+// that closes it resumes the string. An invalid escape sequence is reported once the part is
+// consumed, so scanning resumes after it. The line and column are the ones of the whole string.
+func (s *Scanner) stringPart(line, column int) error {
+	var value strings.Builder
+	var invalid error
 	for s.iterator.peek() != '"' && !s.iterator.isAtEnd() {
 		r := s.iterator.advance()
-		value += string(r)
+		if r == '$' && s.iterator.match('{') {
+			s.interpolations = append(s.interpolations, &interpolation{line: line, column: column})
+			if invalid != nil {
+				return invalid
+			}
+
+			s.addToken(INTERPOLATION, value.String())
+			return nil
+		}
+
+		if r != '\\' {
+			value.WriteRune(r)
+			continue
+		}
+
+		escapeLine, escapeColumn := s.iterator.line, s.iterator.column
+		r, sequence, ok := s.escape()
+		if !ok && invalid == nil {
+			invalid = InvalidEscapeSequenceError(sequence, escapeLine, escapeColumn)
+		}
+		value.WriteRune(r)
+	}
+
+	if s.iterator.isAtEnd() {
+		return UnterminatedStringError(line, column)
+	}
+
+	s.iterator.advance()
+	if invalid != nil {
+		return invalid
+	}
+
+	s.addToken(STRING, value.String())
+	return nil
+}
+
+// escape scans the escape sequence after a backslash and returns the rune it stands for, it is
+// not ok when the sequence is not valid
+func (s *Scanner) escape() (rune, string, bool) {
+	if s.iterator.isAtEnd() {
+		return 0, "\\", false
+	}
+
+	r := s.iterator.advance()
+	switch r {
+	case 'n':
+		return '\n', "", true
+	case 't':
+		return '\t', "", true
+	case 'r':
+		return '\r', "", true
+	case '0':
+		return 0, "", true
+	case '"', '\\', '$':
+		return r, "", true
+	case 'u':
+		return s.unicodeEscape()
+	}
+	return 0, "\\" + string(r), false
+}
+
+// unicodeEscape scans the hexadecimal code point of a \u{1F600} escape sequence
+func (s *Scanner) unicodeEscape() (rune, string, bool) {
+	sequence := "\\u"
+	if !s.iterator.match('{') {
+		return 0, sequence, false
+	}
+
+	var digits string
+	for isHexDigit(s.iterator.peek()) {
+		digits += string(s.iterator.advance())
+	}
+
+	sequence += "{" + digits
+	if !s.iterator.match('}') {
+		return 0, sequence, false
+	}
+	sequence += "}"
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, sequence, false
+	}
+	return rune(code), "", true
+}
+
+// rawString scans a string literal between backticks, which may span several lines and whose
+// content is taken verbatim, as in Go
+func (s *Scanner) rawString() error {
+	for s.iterator.peek() != '`' && !s.iterator.isAtEnd() {
+		s.iterator.advance()
 	}
 
 	if s.iterator.isAtEnd() {
-		return UnterminatedStringError(s.iterator.line, s.iterator.column)
+		return UnterminatedStringError(s.iterator.startLine, s.iterator.startColumn)
 	}
 
 	s.iterator.advance()
-	s.addToken(STRING, value)
+	s.addToken(STRING, string(s.iterator.source[s.iterator.start+1:s.iterator.current-1]))
 	return nil
 }
 
@@ -227,7 +380,7 @@ func (s *Scanner) addToken(t TokenType, literal interface{}) {
 		tokenType: t,
 		lexeme:    s.iterator.currentLexeme(),
 		literal:   literal,
-		line:      s.iterator.line,
-		column:    s.iterator.column,
+		line:      s.iterator.startLine,
+		column:    s.iterator.startColumn,
 	})
 }
@@ -0,0 +1,35 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"testing"
+)
+
+func TestScanner_ScanTokensRecovers(t *testing.T) {
+	tokens, errs := lox.NewScanner("var a = 1 @ 2;\nprint a $ 3;\nprint \"open;").ScanTokens()
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+
+	expected := []string{
+		"SyntaxError [Line: 1, Column: 11] : unexpected token '@'. Code UnexpectedToken",
+		"SyntaxError [Line: 2, Column: 9] : unexpected token '$'. Code UnexpectedToken",
+		"SyntaxError [Line: 3, Column: 7] : unterminated string. Code UnterminatedString",
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], err.Error())
+		}
+	}
+
+	errors := 0
+	for _, token := range tokens {
+		if token.Is(lox.ERROR) {
+			errors++
+		}
+	}
+
+	if errors != 3 || !tokens[len(tokens)-1].Is(lox.EOF) {
+		t.Fatalf("expected an error token per error followed by EOF")
+	}
+}
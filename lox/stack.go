@@ -10,6 +10,8 @@ type ScopeEntry struct {
 	defined bool
 	used    bool
 	token   *Token
+	// symbol of the entry when the program is being analyzed
+	symbol *Symbol
 }
 
 // NewScopeStack constructor
@@ -58,8 +60,7 @@ func (s *ScopeStack) Peek() (map[string]*ScopeEntry, error) {
 }
 
 func (s *ScopeStack) Get(i uint) (map[string]*ScopeEntry, error) {
-	l := uint(len(s.s))
-	if i > l-1 {
+	if i >= uint(len(s.s)) {
 		return nil, errors.New("index out of range")
 	}
 
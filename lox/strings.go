@@ -0,0 +1,209 @@
+package lox
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringMethods are called on string values, as s.upper(). Positions and lengths count runes, not
+// bytes, so they work on text that is not ASCII.
+var stringMethods = map[string]func(s string) *NativeFunction{
+	"len": func(s string) *NativeFunction {
+		return NewNativeFunction(0, false, func(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			return float64(utf8.RuneCountInString(s)), nil
+		})
+	},
+	"upper": func(s string) *NativeFunction {
+		return stringTransform(s, strings.ToUpper)
+	},
+	"lower": func(s string) *NativeFunction {
+		return stringTransform(s, strings.ToLower)
+	},
+	"trim": func(s string) *NativeFunction {
+		return stringTransform(s, strings.TrimSpace)
+	},
+	"at": func(s string) *NativeFunction {
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			runes := []rune(s)
+			n, err := index(paren, arguments[0], len(runes))
+			if err != nil {
+				return nil, err
+			}
+			return string(runes[n]), nil
+		})
+	},
+	"substring": func(s string) *NativeFunction {
+		// substring from a start to an optional end, which defaults to the end of the string
+		return NewNativeFunction(1, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			if len(arguments) > 2 {
+				return nil, WrongNumberOfArguments(paren, len(arguments), 2)
+			}
+
+			runes := []rune(s)
+			start, err := index(paren, arguments[0], len(runes)+1)
+			if err != nil {
+				return nil, err
+			}
+
+			end := len(runes)
+			if len(arguments) == 2 {
+				end, err = index(paren, arguments[1], len(runes)+1)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if end < start {
+				return "", nil
+			}
+			return i.newString(string(runes[start:end])), nil
+		})
+	},
+	"indexOf": func(s string) *NativeFunction {
+		// indexOf returns the position of the first occurrence of a substring, -1 when there is none
+		return stringFunction(s, func(substring string) interface{} {
+			n := strings.Index(s, substring)
+			if n < 0 {
+				return float64(-1)
+			}
+			return float64(utf8.RuneCountInString(s[:n]))
+		})
+	},
+	"contains": func(s string) *NativeFunction {
+		return stringFunction(s, func(substring string) interface{} {
+			return strings.Contains(s, substring)
+		})
+	},
+	"startsWith": func(s string) *NativeFunction {
+		return stringFunction(s, func(prefix string) interface{} {
+			return strings.HasPrefix(s, prefix)
+		})
+	},
+	"endsWith": func(s string) *NativeFunction {
+		return stringFunction(s, func(suffix string) interface{} {
+			return strings.HasSuffix(s, suffix)
+		})
+	},
+	"split": func(s string) *NativeFunction {
+		// split around every occurrence of a separator, an empty one splits every rune
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			separator, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			parts := strings.Count(s, separator) + 1
+			if !i.fits(len(s) + parts*(stringSize+valueSize)) {
+				return nil, MemoryLimitExceeded(paren, "bytes", i.memoryLimit)
+			}
+
+			var values []interface{}
+			for _, part := range strings.Split(s, separator) {
+				values = append(values, i.newString(part))
+			}
+			return i.newList(values), nil
+		})
+	},
+	"replace": func(s string) *NativeFunction {
+		// replace every occurrence of a substring
+		return NewNativeFunction(2, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			old, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			replacement, err := stringArgument(paren, arguments[1])
+			if err != nil {
+				return nil, err
+			}
+
+			if err := i.reserve(paren, len(s)+strings.Count(s, old)*(len(replacement)-len(old))); err != nil {
+				return nil, err
+			}
+			return strings.ReplaceAll(s, old, replacement), nil
+		})
+	},
+	"format": func(s string) *NativeFunction {
+		// format the arguments with the string as layout, as the format function does
+		return NewNativeFunction(0, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			formatted, err := i.sprintf(paren, s, arguments)
+			if err != nil {
+				return nil, err
+			}
+			return i.newString(formatted), nil
+		})
+	},
+}
+
+// stringTransform method that returns a new string made out of the string
+func stringTransform(s string, transform func(string) string) *NativeFunction {
+	return NewNativeFunction(0, false, func(i *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+		return i.newString(transform(s)), nil
+	})
+}
+
+// stringFunction method of a string that takes another one
+func stringFunction(s string, f func(string) interface{}) *NativeFunction {
+	return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		argument, err := stringArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(argument), nil
+	})
+}
+
+func stringArgument(paren *Token, argument interface{}) (string, error) {
+	s, ok := argument.(string)
+	if !ok {
+		return "", InvalidDataTypeError(paren, getDataType(argument), str)
+	}
+	return s, nil
+}
+
+// index returns the argument as a position in a sequence of the given length
+func index(paren *Token, argument interface{}, length int) (int, error) {
+	v, ok := argument.(float64)
+	if !ok {
+		return 0, InvalidDataTypeError(paren, getDataType(argument), number)
+	}
+
+	n := int(v)
+	if float64(n) != v || n < 0 || n >= length {
+		return 0, InvalidIndex(paren, v, length)
+	}
+	return n, nil
+}
+
+// NewStrFunction constructor
+func NewStrFunction() *NativeFunction {
+	// str converts any value to the string print shows
+	return NewNativeFunction(1, false, func(i *Interpreter, _ *Token, arguments []interface{}) (interface{}, error) {
+		if s, ok := arguments[0].(string); ok {
+			return s, nil
+		}
+		return i.newString(Stringify(arguments[0])), nil
+	})
+}
+
+// NewNumFunction constructor
+func NewNumFunction() *NativeFunction {
+	// num parses a number out of a string, surrounding spaces are ignored
+	return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		if n, ok := arguments[0].(float64); ok {
+			return n, nil
+		}
+
+		s, err := stringArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, InvalidNumber(paren, s)
+		}
+		return n, nil
+	})
+}
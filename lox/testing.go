@@ -0,0 +1,110 @@
+package lox
+
+import (
+	"strings"
+	"time"
+)
+
+// TestResult of running a test function
+type TestResult struct {
+	Name string
+	// Duration of the test, including the top level code of the program that runs before it
+	Duration time.Duration
+	// Err made the test fail, it is nil when the test passed
+	Err error
+	// Failure describes the error, and where it happened
+	Failure Diagnostic
+}
+
+// Passed is true when the test did not raise any error
+func (r *TestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// Tests returns the test functions of a program, the functions declared at the top level whose
+// names start with "test" and that take no parameters
+func Tests(program []Stmt) []string {
+	var tests []string
+	for _, s := range topLevel(program) {
+		f, ok := s.(*FunctionStmt)
+		if ok && f.name != nil && strings.HasPrefix(f.name.lexeme, "test") && len(f.params) == 0 {
+			tests = append(tests, f.name.lexeme)
+		}
+	}
+	return tests
+}
+
+// topLevel statements of a program, which the parser wraps in a block
+func topLevel(program []Stmt) []Stmt {
+	if len(program) == 1 {
+		if b, ok := program[0].(*BlockStmt); ok && b.brace == nil {
+			return b.statements
+		}
+	}
+	return program
+}
+
+// RunTests runs the test functions of a program whose names match, each one in an interpreter of
+// its own so they don't share any state. Errors that prevent the program from running at all, as
+// unused variables, are returned instead of failing every test.
+func RunTests(program []Stmt, match func(name string) bool, options ...Option) ([]TestResult, error) {
+	tests := Tests(program)
+	if _, err := NewResolver(NewInterpreter(options...)).Resolve(testProgram(program, tests, "")); err != nil {
+		return nil, err
+	}
+
+	var results []TestResult
+	for _, name := range tests {
+		if !match(name) {
+			continue
+		}
+
+		result := TestResult{Name: name}
+		start := time.Now()
+		result.Err = runTest(program, tests, name, options)
+		result.Duration = time.Since(start)
+		if result.Err != nil {
+			result.Failure = diagnostic(result.Err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// runTest runs the top level code of the program and then calls the test function
+func runTest(program []Stmt, tests []string, name string, options []Option) error {
+	program = testProgram(program, tests, name)
+
+	i := NewInterpreter(options...)
+	defer i.Close()
+
+	if _, err := NewResolver(i).Resolve(program); err != nil {
+		return err
+	}
+
+	return i.Interpret(program)
+}
+
+// testProgram appends to the top level of a program a call to the given test function, and
+// references to the rest of them so they are not reported as unused
+func testProgram(program []Stmt, tests []string, name string) []Stmt {
+	declarations := map[string]*Token{}
+	for _, s := range topLevel(program) {
+		if f, ok := s.(*FunctionStmt); ok && f.name != nil {
+			declarations[f.name.lexeme] = f.name
+		}
+	}
+
+	statements := append([]Stmt{}, topLevel(program)...)
+	for _, test := range tests {
+		t := declarations[test]
+		var e Expression = NewVariable(NewToken(IDENTIFIER, test, nil, t.line, t.column))
+		if test == name {
+			e = NewCall(e, NewToken(RIGHT_PAREN, ")", nil, t.line, t.column), nil)
+		}
+		statements = append(statements, NewExpressionStmt(e))
+	}
+
+	return []Stmt{NewBlockStmt(statements, nil)}
+}
@@ -0,0 +1,78 @@
+package lox_test
+
+import (
+	"golox/lox"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const suite = `var calls = 0;
+
+fun double(n) {
+    return n * 2;
+}
+
+fun testDouble() {
+    calls = calls + 1;
+    assertEqual(4, double(2));
+    assertEqual(1, calls);
+}
+
+fun testValue(n) {
+    return n;
+}
+
+fun testIsolated() {
+    calls = calls + 1;
+    assert(testValue(calls) == 1, "tests share state");
+}
+
+fun testFails() {
+    assertEqual("4", double(2));
+}
+
+fun testThrows() {
+    var divide = fun() {
+        return 1 / 0;
+    };
+    assertEqual("division by zero is not supported", assertThrows(divide));
+}
+`
+
+func TestRunTests(t *testing.T) {
+	stmts := parse(t, suite)
+
+	if tests := lox.Tests(stmts); !reflect.DeepEqual(tests, []string{"testDouble", "testIsolated", "testFails", "testThrows"}) {
+		t.Fatalf("unexpected tests %v", tests)
+	}
+
+	results, err := lox.RunTests(stmts, regexp.MustCompile("").MatchString, lox.WithOutput(ioutil.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := map[string]lox.Diagnostic{}
+	for _, r := range results {
+		if !r.Passed() {
+			failed[r.Name] = r.Failure
+		}
+	}
+
+	expected := map[string]lox.Diagnostic{
+		"testFails": {
+			Position:    lox.Position{Line: 23, Column: 31},
+			Code:        lox.AssertionFailedCode,
+			Description: `expected "4", got 4`,
+		},
+	}
+	if len(results) != 4 || !reflect.DeepEqual(failed, expected) {
+		t.Fatalf("unexpected failures %+v", failed)
+	}
+
+	results, err = lox.RunTests(stmts, regexp.MustCompile("Isolated$").MatchString, lox.WithOutput(ioutil.Discard))
+	if err != nil || len(results) != 1 || results[0].Name != "testIsolated" {
+		t.Fatalf("expected to run testIsolated only, got %+v, %v", results, err)
+	}
+}
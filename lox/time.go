@@ -0,0 +1,434 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// timeSize is the estimated size of a date or a duration
+const timeSize = 32
+
+// NewTimeNamespace constructor of the time namespace. Reading the current time and sleeping need
+// the clock capability, the rest works on the dates and durations it is given.
+func NewTimeNamespace() *Namespace {
+	return NewNamespace("time", map[string]interface{}{
+		// layouts of parse and format, written as Go's reference time
+		"RFC3339":  time.RFC3339,
+		"DateTime": "2006-01-02 15:04:05",
+		"DateOnly": "2006-01-02",
+		"TimeOnly": "15:04:05",
+
+		"millisecond": &Duration{time.Millisecond},
+		"second":      &Duration{time.Second},
+		"minute":      &Duration{time.Minute},
+		"hour":        &Duration{time.Hour},
+
+		"now": NewNativeFunction(0, false, func(i *Interpreter, paren *Token, _ []interface{}) (interface{}, error) {
+			// now returns the milliseconds elapsed since the Unix epoch
+			if err := i.require(paren, ClockCapability); err != nil {
+				return nil, err
+			}
+			return float64(i.clock.Now().UnixNano()) / float64(time.Millisecond), nil
+		}),
+		"sleep": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// sleep for a number of milliseconds or a duration
+			if err := i.require(paren, ClockCapability); err != nil {
+				return nil, err
+			}
+
+			d, err := durationArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			if d < 0 {
+				return nil, InvalidTime(paren, fmt.Sprintf("can't sleep for a negative duration %v", d))
+			}
+			return nil, i.sleep(paren, d)
+		}),
+		"Date": NewNativeFunction(0, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// Date returns the current date without arguments, the date a number of milliseconds
+			// after the Unix epoch, or the date of a year, month and day, optionally followed by
+			// hours, minutes and seconds, in UTC
+			if len(arguments) == 0 {
+				if err := i.require(paren, ClockCapability); err != nil {
+					return nil, err
+				}
+				return i.newDate(i.clock.Now()), nil
+			}
+
+			x, err := numbers(paren, arguments)
+			if err != nil {
+				return nil, err
+			}
+
+			switch len(x) {
+			case 1:
+				ms, err := milliseconds(paren, x[0])
+				if err != nil {
+					return nil, err
+				}
+				return i.newDate(time.Unix(0, 0).UTC().Add(ms)), nil
+			case 3, 4, 5, 6:
+				parts := make([]float64, 6)
+				copy(parts, x)
+				for _, part := range parts[:5] {
+					if part != math.Trunc(part) || math.Abs(part) > math.MaxInt32 {
+						return nil, InvalidTime(paren, fmt.Sprintf("%v is not a valid part of a date", part))
+					}
+				}
+				if math.Abs(parts[5]) > math.MaxInt32 {
+					return nil, InvalidTime(paren, fmt.Sprintf("%v is not a valid part of a date", parts[5]))
+				}
+
+				seconds, fraction := math.Modf(parts[5])
+				t := time.Date(int(parts[0]), time.Month(parts[1]), int(parts[2]), int(parts[3]), int(parts[4]),
+					int(seconds), int(fraction*float64(time.Second)), time.UTC)
+				return i.newDate(t), nil
+			}
+			return nil, WrongNumberOfArguments(paren, len(arguments), 3)
+		}),
+		"Duration": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// Duration of a number of milliseconds
+			d, err := durationArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDuration(d), nil
+		}),
+		"parse": NewNativeFunction(2, true, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// parse a date with a layout, in UTC or the time zone given as third argument unless
+			// the text has one
+			if len(arguments) > 3 {
+				return nil, WrongNumberOfArguments(paren, len(arguments), 3)
+			}
+
+			layout, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			text, err := stringArgument(paren, arguments[1])
+			if err != nil {
+				return nil, err
+			}
+
+			location := time.UTC
+			if len(arguments) == 3 {
+				if location, err = zoneArgument(paren, arguments[2]); err != nil {
+					return nil, err
+				}
+			}
+
+			t, err := time.ParseInLocation(layout, text, location)
+			if err != nil {
+				return nil, InvalidTime(paren, err.Error())
+			}
+			return i.newDate(t), nil
+		}),
+		"parseDuration": NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			// parseDuration of a text as "1h30m" or "250ms"
+			text, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			d, err := time.ParseDuration(text)
+			if err != nil {
+				return nil, InvalidTime(paren, err.Error())
+			}
+			return i.newDuration(d), nil
+		}),
+	})
+}
+
+// sleep for the duration with the clock of the interpreter. It wakes up when the deadline passes
+// or the context is done and reports it.
+func (i *Interpreter) sleep(t *Token, d time.Duration) error {
+	ctx := i.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !i.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, i.deadline)
+		defer cancel()
+	}
+
+	i.clock.Sleep(ctx, d)
+	return i.limit(t)
+}
+
+// Date is an instant with the time zone it is shown in
+type Date struct {
+	t time.Time
+}
+
+func (d *Date) String() string {
+	return d.t.Format(time.RFC3339Nano)
+}
+
+// Duration elapsed between two dates
+type Duration struct {
+	d time.Duration
+}
+
+func (d *Duration) String() string {
+	return d.d.String()
+}
+
+// newDate allocates a date
+func (i *Interpreter) newDate(t time.Time) *Date {
+	i.allocate(timeSize)
+	return &Date{t}
+}
+
+// newDuration allocates a duration
+func (i *Interpreter) newDuration(d time.Duration) *Duration {
+	i.allocate(timeSize)
+	return &Duration{d}
+}
+
+// dateMethods are called on dates, as d.year()
+var dateMethods = map[string]func(d *Date) *NativeFunction{
+	"year": func(d *Date) *NativeFunction {
+		return dateField(d.t.Year)
+	},
+	"month": func(d *Date) *NativeFunction {
+		return dateField(func() int {
+			return int(d.t.Month())
+		})
+	},
+	"day": func(d *Date) *NativeFunction {
+		return dateField(d.t.Day)
+	},
+	"hour": func(d *Date) *NativeFunction {
+		return dateField(d.t.Hour)
+	},
+	"minute": func(d *Date) *NativeFunction {
+		return dateField(d.t.Minute)
+	},
+	"second": func(d *Date) *NativeFunction {
+		return dateField(d.t.Second)
+	},
+	"millisecond": func(d *Date) *NativeFunction {
+		return dateField(func() int {
+			return d.t.Nanosecond() / int(time.Millisecond)
+		})
+	},
+	"weekday": func(d *Date) *NativeFunction {
+		// weekday from 0 on Sunday to 6 on Saturday
+		return dateField(func() int {
+			return int(d.t.Weekday())
+		})
+	},
+	"yearDay": func(d *Date) *NativeFunction {
+		return dateField(d.t.YearDay)
+	},
+	"unix": func(d *Date) *NativeFunction {
+		// unix returns the milliseconds elapsed since the Unix epoch
+		return NewNativeFunction(0, false, func(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			return float64(d.t.UnixNano()) / float64(time.Millisecond), nil
+		})
+	},
+	"zone": func(d *Date) *NativeFunction {
+		// zone returns the abbreviated name of the time zone, as "UTC" or "CET"
+		return NewNativeFunction(0, false, func(i *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			name, _ := d.t.Zone()
+			return i.newString(name), nil
+		})
+	},
+	"format": func(d *Date) *NativeFunction {
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			layout, err := stringArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newString(d.t.Format(layout)), nil
+		})
+	},
+	"in": func(d *Date) *NativeFunction {
+		// in returns the same instant in a time zone of the tz database, as "America/New_York"
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			location, err := zoneArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDate(d.t.In(location)), nil
+		})
+	},
+	"utc": func(d *Date) *NativeFunction {
+		return NewNativeFunction(0, false, func(i *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			return i.newDate(d.t.UTC()), nil
+		})
+	},
+	"local": func(d *Date) *NativeFunction {
+		return NewNativeFunction(0, false, func(i *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+			return i.newDate(d.t.Local()), nil
+		})
+	},
+	"add": func(d *Date) *NativeFunction {
+		// add a duration, or a number of milliseconds
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			duration, err := durationArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDate(d.t.Add(duration)), nil
+		})
+	},
+	"addDate": func(d *Date) *NativeFunction {
+		// addDate adds years, months and days, normalizing the result as 31 October plus one
+		// month is 1 December
+		return NewNativeFunction(3, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			x, err := numbers(paren, arguments)
+			if err != nil {
+				return nil, err
+			}
+			for _, part := range x {
+				if part != math.Trunc(part) || math.Abs(part) > math.MaxInt32 {
+					return nil, InvalidTime(paren, fmt.Sprintf("%v is not a valid part of a date", part))
+				}
+			}
+			return i.newDate(d.t.AddDate(int(x[0]), int(x[1]), int(x[2]))), nil
+		})
+	},
+	"sub": func(d *Date) *NativeFunction {
+		// sub returns the duration elapsed since another date
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			other, err := dateArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDuration(d.t.Sub(other.t)), nil
+		})
+	},
+	"before": func(d *Date) *NativeFunction {
+		return dateComparison(d, d.t.Before)
+	},
+	"after": func(d *Date) *NativeFunction {
+		return dateComparison(d, d.t.After)
+	},
+	"equal": func(d *Date) *NativeFunction {
+		// equal tells whether both dates are the same instant, even in different time zones
+		return dateComparison(d, d.t.Equal)
+	},
+}
+
+// durationMethods are called on durations, as d.seconds()
+var durationMethods = map[string]func(d *Duration) *NativeFunction{
+	"milliseconds": func(d *Duration) *NativeFunction {
+		return durationIn(d, time.Millisecond)
+	},
+	"seconds": func(d *Duration) *NativeFunction {
+		return durationIn(d, time.Second)
+	},
+	"minutes": func(d *Duration) *NativeFunction {
+		return durationIn(d, time.Minute)
+	},
+	"hours": func(d *Duration) *NativeFunction {
+		return durationIn(d, time.Hour)
+	},
+	"add": func(d *Duration) *NativeFunction {
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			other, err := durationArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDuration(d.d + other), nil
+		})
+	},
+	"sub": func(d *Duration) *NativeFunction {
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			other, err := durationArgument(paren, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return i.newDuration(d.d - other), nil
+		})
+	},
+	"mul": func(d *Duration) *NativeFunction {
+		// mul returns the duration multiplied by a number
+		return NewNativeFunction(1, false, func(i *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+			x, err := numbers(paren, arguments)
+			if err != nil {
+				return nil, err
+			}
+
+			product := float64(d.d) * x[0]
+			if math.IsNaN(product) || math.Abs(product) >= math.MaxInt64 {
+				return nil, InvalidTime(paren, fmt.Sprintf("%v times %v is out of range", d, x[0]))
+			}
+			return i.newDuration(time.Duration(product)), nil
+		})
+	},
+}
+
+// dateField method that returns a part of a date
+func dateField(field func() int) *NativeFunction {
+	return NewNativeFunction(0, false, func(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+		return float64(field()), nil
+	})
+}
+
+// dateComparison method of a date that takes another one
+func dateComparison(d *Date, compare func(time.Time) bool) *NativeFunction {
+	return NewNativeFunction(1, false, func(_ *Interpreter, paren *Token, arguments []interface{}) (interface{}, error) {
+		other, err := dateArgument(paren, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		return compare(other.t), nil
+	})
+}
+
+// durationIn method that returns a duration as a number of the given unit
+func durationIn(d *Duration, unit time.Duration) *NativeFunction {
+	return NewNativeFunction(0, false, func(_ *Interpreter, _ *Token, _ []interface{}) (interface{}, error) {
+		return float64(d.d) / float64(unit), nil
+	})
+}
+
+func dateArgument(paren *Token, argument interface{}) (*Date, error) {
+	d, ok := argument.(*Date)
+	if !ok {
+		return nil, InvalidDataTypeError(paren, getDataType(argument), date)
+	}
+	return d, nil
+}
+
+// durationArgument takes either a duration or a number of milliseconds
+func durationArgument(paren *Token, argument interface{}) (time.Duration, error) {
+	switch v := argument.(type) {
+	case *Duration:
+		return v.d, nil
+	case float64:
+		return milliseconds(paren, v)
+	}
+	return 0, InvalidDataTypeError(paren, getDataType(argument), duration)
+}
+
+// milliseconds returns a number of milliseconds as a duration, which is bounded to about 292 years
+func milliseconds(paren *Token, ms float64) (time.Duration, error) {
+	ns := ms * float64(time.Millisecond)
+	if math.IsNaN(ns) || math.Abs(ns) >= math.MaxInt64 {
+		return 0, InvalidTime(paren, fmt.Sprintf("%v milliseconds is out of range", ms))
+	}
+	return time.Duration(ns), nil
+}
+
+// zoneArgument loads a time zone of the tz database by its name
+func zoneArgument(paren *Token, argument interface{}) (*time.Location, error) {
+	name, err := stringArgument(paren, argument)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, InvalidTime(paren, fmt.Sprintf("unknown time zone %q", name))
+	}
+	return location, nil
+}
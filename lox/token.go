@@ -35,6 +35,10 @@ const (
 	IDENTIFIER TokenType = "identifier"
 	STRING     TokenType = "string"
 	NUMBER     TokenType = "number"
+	// INTERPOLATION is the part of an interpolated string before an embedded expression, from the
+	// opening quote or the closing brace of the previous expression to the ${ that starts it. Its
+	// literal is the text of the part. The last part of the string is a STRING token.
+	INTERPOLATION TokenType = "interpolation"
 
 	// Keywords.
 
@@ -57,6 +61,11 @@ const (
 	VAR      TokenType = "var"
 
 	EOF TokenType = "eof"
+	// COMMENT is only emitted by scanners that keep the comments
+	COMMENT TokenType = "comment"
+	// ERROR is emitted by the scanner in place of a lexeme it could not scan. Its literal is the
+	// error that was raised.
+	ERROR TokenType = "error"
 )
 
 var Reserved = map[string]TokenType{
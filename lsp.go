@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"golox/lsp"
+	"os"
+)
+
+// serveLSP runs a language server over the standard input and output
+func serveLSP(args []string) int {
+	if len(args) != 0 {
+		fmt.Print(usage)
+		return 1
+	}
+
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"golox/lox"
+	"strings"
+	"unicode"
+)
+
+// newDocument analyzes the text of a document. The symbols of the previous version are kept
+// while the text has syntax errors, so that completion keeps working in the middle of an edit.
+func newDocument(uri, text string, previous *document) *document {
+	d := &document{
+		uri:      uri,
+		lines:    strings.Split(text, "\n"),
+		analysis: lox.Analyze(text),
+	}
+
+	d.symbols = d.analysis
+	if len(d.analysis.Symbols) == 0 && previous != nil {
+		d.symbols = previous.symbols
+	}
+	return d
+}
+
+type document struct {
+	uri      string
+	lines    []string
+	analysis *lox.Analysis
+	// symbols is the last analysis of the document that has symbols
+	symbols *lox.Analysis
+}
+
+// position converts a position of the interpreter, whose columns count runes from 1, to a
+// protocol one
+func (d *document) position(p lox.Position) Position {
+	line, column := p.Line-1, p.Column-1
+	if line < 0 || line >= len(d.lines) {
+		return Position{}
+	}
+
+	runes := []rune(d.lines[line])
+	if column < 0 {
+		column = 0
+	}
+	if column > len(runes) {
+		column = len(runes)
+	}
+	return Position{Line: line, Character: utf16Len(runes[:column])}
+}
+
+// loxPosition converts a protocol position to a position of the interpreter
+func (d *document) loxPosition(p Position) lox.Position {
+	if p.Line < 0 || p.Line >= len(d.lines) {
+		return lox.Position{Line: p.Line + 1, Column: 1}
+	}
+
+	units := 0
+	column := 0
+	for _, r := range d.lines[p.Line] {
+		if units >= p.Character {
+			break
+		}
+		units += utf16Len([]rune{r})
+		column++
+	}
+	return lox.Position{Line: p.Line + 1, Column: column + 1}
+}
+
+// span returns the range of the given amount of runes starting at a position
+func (d *document) span(p lox.Position, length int) Range {
+	return Range{
+		Start: d.position(p),
+		End:   d.position(lox.Position{Line: p.Line, Column: p.Column + length}),
+	}
+}
+
+// word returns the range of the identifier that starts at a position, or of its first rune when
+// there is none
+func (d *document) word(p lox.Position) Range {
+	length := 0
+	if p.Line >= 1 && p.Line <= len(d.lines) && p.Column >= 1 {
+		runes := []rune(d.lines[p.Line-1])
+		for i := p.Column - 1; i < len(runes) && isIdentifier(runes[i]); i++ {
+			length++
+		}
+	}
+
+	if length == 0 {
+		length = 1
+	}
+	return d.span(p, length)
+}
+
+// prefix returns the identifier that ends at a position, and whether it follows a dot
+func (d *document) prefix(p lox.Position) (string, bool) {
+	if p.Line < 1 || p.Line > len(d.lines) {
+		return "", false
+	}
+
+	runes := []rune(d.lines[p.Line-1])
+	end := p.Column - 1
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	start := end
+	for start > 0 && isIdentifier(runes[start-1]) {
+		start--
+	}
+	return string(runes[start:end]), start > 0 && runes[start-1] == '.'
+}
+
+func isIdentifier(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func utf16Len(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
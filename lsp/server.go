@@ -0,0 +1,386 @@
+// Package lsp implements a Language Server Protocol server for Lox. It publishes the lexical,
+// syntax and resolution errors of the open documents as diagnostics, and supports go to
+// definition, find references, hover, document symbols and completion.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golox/lox"
+	"golox/rpc"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ErrExitWithoutShutdown is returned by Run when the client exits without shutting down the
+// server first
+var ErrExitWithoutShutdown = errors.New("lsp: exit notification received before shutdown")
+
+// NewServer constructor, the server reads messages from in and writes them to out
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:        rpc.NewReader(in),
+		out:       rpc.NewWriter(out),
+		documents: map[string]*document{},
+	}
+}
+
+// Server of the language server protocol
+type Server struct {
+	in          *rpc.Reader
+	out         *rpc.Writer
+	documents   map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// handler of a request, it returns the result of the request
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var requests = map[string]handler{
+	"shutdown":                    (*Server).handleShutdown,
+	"textDocument/definition":     (*Server).handleDefinition,
+	"textDocument/references":     (*Server).handleReferences,
+	"textDocument/hover":          (*Server).handleHover,
+	"textDocument/documentSymbol": (*Server).handleDocumentSymbol,
+	"textDocument/completion":     (*Server).handleCompletion,
+}
+
+var notifications = map[string]func(s *Server, params json.RawMessage) error{
+	"textDocument/didOpen":   (*Server).handleDidOpen,
+	"textDocument/didChange": (*Server).handleDidChange,
+	"textDocument/didClose":  (*Server).handleDidClose,
+}
+
+// Run serves the client until it sends the exit notification or closes the input
+func (s *Server) Run() error {
+	for {
+		content, err := s.in.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var m message
+		if err := json.Unmarshal(content, &m); err != nil {
+			if err := s.respondError(nil, parseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		if err := s.handle(&m); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m *message) error {
+	if m.ID == nil {
+		h, ok := notifications[m.Method]
+		if !ok || !s.initialized || s.shutdown {
+			// Unknown notifications and the ones sent out of order are ignored
+			return nil
+		}
+		return h(s, m.Params)
+	}
+
+	switch {
+	case m.Method == "initialize":
+		s.initialized = true
+		return s.respond(m.ID, s.handleInitialize())
+	case !s.initialized:
+		return s.respondError(m.ID, serverNotInitialized, "the server is not initialized")
+	case s.shutdown:
+		return s.respondError(m.ID, invalidRequest, "the server is shutting down")
+	}
+
+	h, ok := requests[m.Method]
+	if !ok {
+		return s.respondError(m.ID, methodNotFound, fmt.Sprintf("method '%s' not found", m.Method))
+	}
+
+	result, err := h(s, m.Params)
+	if err != nil {
+		return s.respondError(m.ID, invalidParams, err.Error())
+	}
+	return s.respond(m.ID, result)
+}
+
+func (s *Server) respond(id *json.RawMessage, result interface{}) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return s.out.Write(&message{JSONRPC: "2.0", ID: id, Result: b})
+}
+
+func (s *Server) respondError(id *json.RawMessage, code int, description string) error {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	return s.out.Write(&message{JSONRPC: "2.0", ID: id, Error: &responseError{Code: code, Message: description}})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.out.Write(&message{JSONRPC: "2.0", Method: method, Params: b})
+}
+
+func (s *Server) handleInitialize() *InitializeResult {
+	r := &InitializeResult{}
+	r.ServerInfo.Name = "golox"
+	r.Capabilities.TextDocumentSync = 1
+	r.Capabilities.DefinitionProvider = true
+	r.Capabilities.ReferencesProvider = true
+	r.Capabilities.HoverProvider = true
+	r.Capabilities.DocumentSymbolProvider = true
+	r.Capabilities.CompletionProvider.TriggerCharacters = []string{"."}
+	return r
+}
+
+func (s *Server) handleShutdown(_ json.RawMessage) (interface{}, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) handleDidOpen(params json.RawMessage) error {
+	var p DidOpenTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	return s.update(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) handleDidChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil || len(p.ContentChanges) == 0 {
+		return nil
+	}
+	return s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) handleDidClose(params json.RawMessage) error {
+	var p DidCloseTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+
+	delete(s.documents, p.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+// update analyzes the new text of a document and publishes its diagnostics
+func (s *Server) update(uri, text string) error {
+	d := newDocument(uri, text, s.documents[uri])
+	s.documents[uri] = d
+
+	diagnostics := []Diagnostic{}
+	for _, diagnostic := range d.analysis.Diagnostics {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.word(diagnostic.Position),
+			Severity: SeverityError,
+			Code:     diagnostic.Code,
+			Source:   "golox",
+			Message:  diagnostic.Description,
+		})
+	}
+
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// occurrence returns the document and the occurrence of a symbol at the position of a request
+func (s *Server) occurrence(params json.RawMessage, p interface{}, tdp *TextDocumentPositionParams) (*document, *lox.Occurrence, error) {
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, nil, err
+	}
+
+	d, ok := s.documents[tdp.TextDocument.URI]
+	if !ok {
+		return nil, nil, fmt.Errorf("document '%s' is not open", tdp.TextDocument.URI)
+	}
+
+	o, ok := d.symbols.At(d.loxPosition(tdp.Position))
+	if !ok {
+		return d, nil, nil
+	}
+	return d, &o, nil
+}
+
+func (s *Server) handleDefinition(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	d, o, err := s.occurrence(params, &p, &p)
+	if err != nil || o == nil || o.Symbol.Kind == lox.NativeSymbol {
+		return nil, err
+	}
+
+	return &Location{URI: d.uri, Range: d.span(o.Symbol.Position, o.Length)}, nil
+}
+
+func (s *Server) handleReferences(params json.RawMessage) (interface{}, error) {
+	var p ReferenceParams
+	d, o, err := s.occurrence(params, &p, &p.TextDocumentPositionParams)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := []Location{}
+	if o == nil {
+		return locations, nil
+	}
+
+	for _, r := range d.symbols.References(o.Symbol) {
+		if r.Position == o.Symbol.Position && !p.Context.IncludeDeclaration {
+			continue
+		}
+		locations = append(locations, Location{URI: d.uri, Range: d.span(r.Position, r.Length)})
+	}
+	return locations, nil
+}
+
+func (s *Server) handleHover(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	d, o, err := s.occurrence(params, &p, &p)
+	if err != nil || o == nil {
+		return nil, err
+	}
+
+	value := "```lox\n" + o.Symbol.Detail + "\n```"
+	if c := o.Symbol.Container; c != nil {
+		value += fmt.Sprintf("\n\nDeclared in %s `%s`.", c.Kind, c.Name)
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value},
+		Range:    d.span(o.Position, o.Length),
+	}, nil
+}
+
+func (s *Server) handleDocumentSymbol(params json.RawMessage) (interface{}, error) {
+	var p DocumentSymbolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' is not open", p.TextDocument.URI)
+	}
+
+	return documentSymbols(d, d.symbols.Outline()), nil
+}
+
+var symbolKinds = map[lox.SymbolKind]int{
+	lox.VariableSymbol: SymbolKindVariable,
+	lox.FunctionSymbol: SymbolKindFunction,
+	lox.ClassSymbol:    SymbolKindClass,
+	lox.MethodSymbol:   SymbolKindMethod,
+	lox.FieldSymbol:    SymbolKindField,
+}
+
+// documentSymbols converts the symbols to the protocol type, parameters are left out since they
+// are part of the detail of their function
+func documentSymbols(d *document, symbols []*lox.Symbol) []DocumentSymbol {
+	result := []DocumentSymbol{}
+	for _, symbol := range symbols {
+		kind, ok := symbolKinds[symbol.Kind]
+		if !ok {
+			continue
+		}
+
+		r := d.span(symbol.Position, len([]rune(symbol.Name)))
+		result = append(result, DocumentSymbol{
+			Name:           symbol.Name,
+			Detail:         symbol.Detail,
+			Kind:           kind,
+			Range:          r,
+			SelectionRange: r,
+			Children:       documentSymbols(d, symbol.Children),
+		})
+	}
+	return result
+}
+
+var completionKinds = map[lox.SymbolKind]int{
+	lox.VariableSymbol:  CompletionKindVariable,
+	lox.ParameterSymbol: CompletionKindVariable,
+	lox.FunctionSymbol:  CompletionKindFunction,
+	lox.ClassSymbol:     CompletionKindClass,
+	lox.MethodSymbol:    CompletionKindMethod,
+	lox.FieldSymbol:     CompletionKindField,
+	lox.NativeSymbol:    CompletionKindFunction,
+}
+
+// handleCompletion suggests the methods and fields of every class after a dot, and the keywords
+// and the names in scope otherwise
+func (s *Server) handleCompletion(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' is not open", p.TextDocument.URI)
+	}
+
+	position := d.loxPosition(p.Position)
+	prefix, property := d.prefix(position)
+
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	add := func(label string, kind int, detail string) {
+		if seen[label] || !strings.HasPrefix(label, prefix) {
+			return
+		}
+		seen[label] = true
+		items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+	}
+
+	if property {
+		for _, symbol := range d.symbols.Symbols {
+			if symbol.Kind == lox.MethodSymbol || symbol.Kind == lox.FieldSymbol {
+				add(symbol.Name, completionKinds[symbol.Kind], symbol.Detail)
+			}
+		}
+		return items, nil
+	}
+
+	visible := d.symbols.Visible(position)
+	// Inner symbols shadow the outer ones with the same name
+	for i := len(visible) - 1; i >= 0; i-- {
+		add(visible[i].Name, completionKinds[visible[i].Kind], visible[i].Detail)
+	}
+
+	keywords := make([]string, 0, len(lox.Reserved))
+	for k := range lox.Reserved {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	for _, k := range keywords {
+		add(k, CompletionKindKeyword, "")
+	}
+
+	return items, nil
+}
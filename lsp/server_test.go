@@ -0,0 +1,306 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"golox/lsp"
+	"golox/rpc"
+	"io"
+	"testing"
+)
+
+const uri = "file:///test.lox"
+
+const source = `class Counter {
+    init() {
+        this.count = 0;
+    }
+
+    increment() {
+        this.count = this.count + 1;
+        return this.count;
+    }
+}
+
+fun twice(counter) {
+    counter.increment();
+    return counter.increment();
+}
+
+var c = Counter();
+print twice(c);
+`
+
+type client struct {
+	t    *testing.T
+	in   *rpc.Reader
+	out  *rpc.Writer
+	id   int
+	done chan error
+	// notifications received while waiting for responses
+	notifications []map[string]json.RawMessage
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		t:    t,
+		in:   rpc.NewReader(clientIn),
+		out:  rpc.NewWriter(clientOut),
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		err := lsp.NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.done <- err
+	}()
+
+	c.call("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}, nil)
+	c.notify("initialized", map[string]interface{}{})
+	return c
+}
+
+func (c *client) read() map[string]json.RawMessage {
+	content, err := c.in.Read()
+	if err != nil {
+		c.t.Fatalf("reading message: %v", err)
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(content, &m); err != nil {
+		c.t.Fatalf("decoding %s: %v", content, err)
+	}
+	return m
+}
+
+func (c *client) notify(method string, params interface{}) {
+	err := c.out.Write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends a request and decodes the result of its response into result
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.id++
+	err := c.out.Write(map[string]interface{}{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	for {
+		m := c.read()
+		if _, ok := m["id"]; !ok {
+			c.notifications = append(c.notifications, m)
+			continue
+		}
+
+		if e, ok := m["error"]; ok {
+			c.t.Fatalf("%s failed: %s", method, e)
+		}
+
+		if result != nil {
+			if err := json.Unmarshal(m["result"], result); err != nil {
+				c.t.Fatalf("decoding result of %s: %v", method, err)
+			}
+		}
+		return
+	}
+}
+
+// diagnostics waits for the next diagnostics published by the server
+func (c *client) diagnostics() lsp.PublishDiagnosticsParams {
+	var p lsp.PublishDiagnosticsParams
+	for {
+		var m map[string]json.RawMessage
+		if len(c.notifications) > 0 {
+			m, c.notifications = c.notifications[0], c.notifications[1:]
+		} else {
+			m = c.read()
+		}
+
+		var method string
+		json.Unmarshal(m["method"], &method)
+		if method == "textDocument/publishDiagnostics" {
+			if err := json.Unmarshal(m["params"], &p); err != nil {
+				c.t.Fatal(err)
+			}
+			return p
+		}
+	}
+}
+
+func (c *client) open(text string) lsp.PublishDiagnosticsParams {
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "lox", "version": 1, "text": text},
+	})
+	return c.diagnostics()
+}
+
+func (c *client) exit() {
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func at(line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	c := newClient(t)
+	defer c.exit()
+
+	d := c.open("var unused = 1;\nprint countr;\nvar counter = 0;\nprint counter;\n")
+	if len(d.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", d.Diagnostics)
+	}
+
+	undeclared := d.Diagnostics[0]
+	expected := lsp.Range{Start: lsp.Position{Line: 1, Character: 6}, End: lsp.Position{Line: 1, Character: 12}}
+	if undeclared.Code != "UndeclaredVariable" || undeclared.Range != expected {
+		t.Fatalf("unexpected diagnostic %+v", undeclared)
+	}
+
+	if d.Diagnostics[1].Code != "UnusedVariable" || d.Diagnostics[1].Range.Start.Line != 0 {
+		t.Fatalf("unexpected diagnostic %+v", d.Diagnostics[1])
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": "print (1;\n"}},
+	})
+	d = c.diagnostics()
+	if len(d.Diagnostics) != 1 || d.Diagnostics[0].Code != "UnclosedParenthesis" {
+		t.Fatalf("expected a syntax error, got %+v", d.Diagnostics)
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 3},
+		"contentChanges": []map[string]interface{}{{"text": "print 1;\n"}},
+	})
+	if d = c.diagnostics(); len(d.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", d.Diagnostics)
+	}
+}
+
+func TestServer_DefinitionAndReferences(t *testing.T) {
+	c := newClient(t)
+	defer c.exit()
+	c.open(source)
+
+	// counter in counter.increment()
+	var location lsp.Location
+	c.call("textDocument/definition", at(12, 6), &location)
+	expected := lsp.Range{Start: lsp.Position{Line: 11, Character: 10}, End: lsp.Position{Line: 11, Character: 17}}
+	if location.URI != uri || location.Range != expected {
+		t.Fatalf("unexpected definition %+v", location)
+	}
+
+	// increment in counter.increment()
+	c.call("textDocument/definition", at(12, 14), &location)
+	if location.Range.Start != (lsp.Position{Line: 5, Character: 4}) {
+		t.Fatalf("unexpected definition %+v", location)
+	}
+
+	params := at(2, 14)
+	params["context"] = map[string]interface{}{"includeDeclaration": true}
+	var locations []lsp.Location
+	c.call("textDocument/references", params, &locations)
+	if len(locations) != 4 {
+		t.Fatalf("expected 4 references to count, got %+v", locations)
+	}
+
+	params["context"] = map[string]interface{}{"includeDeclaration": false}
+	c.call("textDocument/references", params, &locations)
+	if len(locations) != 3 || locations[0].Range.Start != (lsp.Position{Line: 6, Character: 13}) {
+		t.Fatalf("unexpected references %+v", locations)
+	}
+}
+
+func TestServer_Hover(t *testing.T) {
+	c := newClient(t)
+	defer c.exit()
+	c.open(source)
+
+	var hover lsp.Hover
+	c.call("textDocument/hover", at(17, 7), &hover)
+	if hover.Contents.Value != "```lox\nfun twice(counter)\n```" {
+		t.Fatalf("unexpected hover %q", hover.Contents.Value)
+	}
+
+	c.call("textDocument/hover", at(5, 6), &hover)
+	if hover.Contents.Value != "```lox\nmethod increment()\n```\n\nDeclared in class `Counter`." {
+		t.Fatalf("unexpected hover %q", hover.Contents.Value)
+	}
+}
+
+func TestServer_DocumentSymbol(t *testing.T) {
+	c := newClient(t)
+	defer c.exit()
+	c.open(source)
+
+	var symbols []lsp.DocumentSymbol
+	c.call("textDocument/documentSymbol", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri}}, &symbols)
+
+	names := []string{}
+	for _, s := range symbols {
+		names = append(names, s.Name)
+	}
+	if len(symbols) != 3 || names[0] != "Counter" || names[1] != "twice" || names[2] != "c" {
+		t.Fatalf("unexpected symbols %v", names)
+	}
+
+	class := symbols[0]
+	if class.Kind != lsp.SymbolKindClass || len(class.Children) != 3 {
+		t.Fatalf("unexpected class symbol %+v", class)
+	}
+	if class.Children[1].Name != "count" || class.Children[1].Kind != lsp.SymbolKindField {
+		t.Fatalf("unexpected field symbol %+v", class.Children[1])
+	}
+}
+
+func TestServer_Completion(t *testing.T) {
+	c := newClient(t)
+	defer c.exit()
+	c.open(source)
+
+	var items []lsp.CompletionItem
+	c.call("textDocument/completion", at(13, 11), &items)
+	labels := map[string]bool{}
+	for _, item := range items {
+		labels[item.Label] = true
+	}
+	for _, expected := range []string{"counter", "clock", "class", "Counter"} {
+		if !labels[expected] {
+			t.Fatalf("expected %s to be suggested, got %+v", expected, items)
+		}
+	}
+
+	// While typing a property access the source does not parse, the last symbols are used
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": source + "c.inc"}},
+	})
+	c.diagnostics()
+
+	c.call("textDocument/completion", at(18, 5), &items)
+	if len(items) != 1 || items[0].Label != "increment" || items[0].Kind != lsp.CompletionKindMethod {
+		t.Fatalf("unexpected completion %+v", items)
+	}
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err != lsp.ErrExitWithoutShutdown {
+		t.Fatalf("expected %v, got %v", lsp.ErrExitWithoutShutdown, err)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"golox/lox"
+	"os"
+	"path/filepath"
+)
+
+// writeProfile of a script to a file, and its report to the standard error
+func writeProfile(p *lox.Profiler, path, script string) error {
+	err := writeFile(path, func(f *os.File) error { return p.WriteProfile(f, filepath.Base(script)) })
+	if err != nil {
+		return err
+	}
+
+	return p.Report(os.Stderr, filepath.Base(script))
+}
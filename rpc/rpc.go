@@ -0,0 +1,102 @@
+// Package rpc implements the base protocol shared by the language server and the debug adapter:
+// messages are JSON documents preceded by a header with their length.
+//
+//	Content-Length: 52\r\n
+//	\r\n
+//	{"jsonrpc":"2.0","id":1,"method":"initialize",...}
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrMissingLength is returned when a message has no Content-Length header
+var ErrMissingLength = errors.New("rpc: missing Content-Length header")
+
+// NewReader constructor
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Reader of framed messages
+type Reader struct {
+	r *bufio.Reader
+}
+
+// Read the content of the next message. It returns io.EOF when the input is closed between
+// messages.
+func (r *Reader) Read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("rpc: invalid header %q", line)
+		}
+
+		name, value := line[:colon], strings.TrimSpace(line[colon+1:])
+		if strings.EqualFold(name, "Content-Length") {
+			length, err = strconv.Atoi(value)
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("rpc: invalid Content-Length %q", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, ErrMissingLength
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r.r, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// NewWriter constructor
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Writer of framed messages, it is safe to use from several goroutines
+type Writer struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// Write a message encoding it as JSON
+func (w *Writer) Write(message interface{}) error {
+	content, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w.w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = w.w.Write(content)
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golox/lox"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// testFlags of the test command
+type testFlags struct {
+	run string
+}
+
+func test(args []string) int {
+	var tf testFlags
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.StringVar(&tf.run, "run", "", "run only the tests whose names match the regular expression")
+	if err := flags.Parse(args); err != nil {
+		fmt.Print(usage)
+		return 1
+	}
+
+	match, err := regexp.Compile(tf.run)
+	if err != nil {
+		fmt.Printf("golox test: invalid -run pattern: %v\n", err)
+		return 1
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !info.IsDir() && (p == path || strings.HasSuffix(p, "_test.lox")) {
+				files = append(files, p)
+			}
+			return nil
+		})
+
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Println("golox test: no test files found")
+		return 1
+	}
+
+	status := 0
+	for _, file := range files {
+		if !testFile(file, match) {
+			status = 1
+		}
+	}
+	return status
+}
+
+// testFile runs the tests of a file printing their results the way go test does, and returns
+// whether all of them passed
+func testFile(path string, match *regexp.Regexp) bool {
+	start := time.Now()
+	passed := func(ok bool) bool {
+		status := "ok  "
+		if !ok {
+			status = "FAIL"
+		}
+		fmt.Printf("%s\t%s\t%.3fs\n", status, path, time.Since(start).Seconds())
+		return ok
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Println(err)
+		return passed(false)
+	}
+
+	stmts, err := parse(b)
+	if err != nil {
+		return passed(false)
+	}
+
+	results, err := lox.RunTests(stmts, match.MatchString, lox.WithCapabilities(lox.AllCapabilities()...))
+	if err != nil {
+		report(err)
+		return passed(false)
+	}
+
+	ok := true
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed() {
+			status = "FAIL"
+			ok = false
+		}
+
+		fmt.Printf("--- %s: %s (%.2fs)\n", status, r.Name, r.Duration.Seconds())
+		if !r.Passed() {
+			fmt.Printf("    %s:%d:%d: %s\n", path, r.Failure.Position.Line, r.Failure.Position.Column, r.Failure.Description)
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("ok  \t%s\t%.3fs [no tests to run]\n", path, time.Since(start).Seconds())
+		return true
+	}
+	return passed(ok)
+}
@@ -9,29 +9,30 @@ import (
 
 func main() {
 	expressions := map[string]string{
-		"Assign":   "name *Token, value Expression",
-		"Binary":   "left Expression, operator *Token, right Expression",
-		"Call":     "callee Expression, paren *Token, arguments []Expression",
-		"Get":      "object Expression, name *Token",
-		"Set":      "object Expression, name *Token, value Expression",
-		"Grouping": "expression Expression",
-		"Logical":  "left Expression, operator *Token, right Expression",
-		"Literal":  "value interface{}",
-		"This":     "keyword *Token",
-		"Unary":    "operator *Token, right Expression",
-		"Variable": "token *Token",
+		"Assign":        "name *Token, value Expression",
+		"Binary":        "left Expression, operator *Token, right Expression",
+		"Call":          "callee Expression, paren *Token, arguments []Expression",
+		"Get":           "object Expression, name *Token",
+		"Set":           "object Expression, name *Token, value Expression",
+		"Grouping":      "expression Expression",
+		"Interpolation": "quote *Token, parts []Expression",
+		"Logical":       "left Expression, operator *Token, right Expression",
+		"Literal":       "value interface{}",
+		"This":          "keyword *Token",
+		"Unary":         "operator *Token, right Expression",
+		"Variable":      "token *Token",
 	}
 
 	statements := map[string]string{
 		"ExpressionStmt":   "expression Expression",
 		"FunctionStmt":     "name *Token, params []*Token, body *BlockStmt, rt *bool",
-		"IfStmt":           "expression Expression, thenBranch *BlockStmt, elseBranch *BlockStmt",
-		"ForStmt":          "initializer Stmt, condition Expression, increment Expression, body *BlockStmt, br *bool, cont *bool",
-		"PrintStmt":        "expression Expression",
+		"IfStmt":           "keyword *Token, expression Expression, thenBranch *BlockStmt, elseBranch *BlockStmt",
+		"ForStmt":          "keyword *Token, initializer Stmt, condition Expression, increment Expression, body *BlockStmt, br *bool, cont *bool",
+		"PrintStmt":        "keyword *Token, expression Expression",
 		"VarStmt":          "name *Token, initializer Stmt",
-		"BlockStmt":        "statements []Stmt",
+		"BlockStmt":        "statements []Stmt, brace *Token",
 		"ClassStmt":        "name *Token, super *Variable, methods []*FunctionStmt",
-		"CircuitBreakStmt": "value *bool, statement Stmt",
+		"CircuitBreakStmt": "keyword *Token, value *bool, statement Stmt",
 	}
 
 	dir, _ := os.Getwd()